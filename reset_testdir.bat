@@ -1,2 +1,3 @@
-ROBOCOPY "D:\Prowler\projects\CNC-list-creator\del\orig" "D:\Prowler\projects\CNC-list-creator\del\for_test" /E /PURGE
-ROBOCOPY "D:\Prowler\projects\CNC-list-creator\proj\bin" "D:\Prowler\projects\CNC-list-creator\del\for_test" "ListMaker.exe" "listMaker_settings.xml"
+cd src
+D:\distr\go1.20.14.windows-386\go\bin\go run ./fixture/gentree "D:\Prowler\projects\CNC-list-creator\del\for_test\src"
+ROBOCOPY "D:\Prowler\projects\CNC-list-creator\proj\bin" "D:\Prowler\projects\CNC-list-creator\del\for_test" "ListMaker.exe"
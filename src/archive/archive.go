@@ -0,0 +1,296 @@
+// Пакет archive - архив готовых заказов: перемещение в TargetDir, zip-архивы заказов,
+// восстановление и правила хранения.
+package archive
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/walker"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// Ссылка списка работ (list.xml) на файл-задание
+type listReference struct {
+	listPath string // Полный путь к list.xml
+	filePath string // Путь к файлу-заданию из FilePath на компьютере, где работает программа
+}
+
+// FolderLocations: Текущее расположение папок после перемещения готовых заказов в архив
+type FolderLocations struct {
+	startDir string
+	moved    map[string]string      // Путь относительно стартовой папки -> новый полный путь
+	totals   map[string]panelTotals // Итоги по панелям папок заказов, упакованных в zip-архив
+}
+
+/**
+ * Locate: Возвращает текущий полный путь к папке по её пути относительно стартовой папки.
+ * Если папка (или одна из вышестоящих) перемещена в архив, путь указывает в архив.
+ */
+func (loc FolderLocations) Locate(relPath string) string {
+	for prefix := relPath; prefix != "." && prefix != ""; prefix = filepath.Dir(prefix) {
+		if target, ok := loc.moved[prefix]; ok {
+			// папки внутри zip-архива открыть нельзя, ссылка ведёт на сам архив
+			if fileio.GetExtension(target) == "zip" {
+				return target
+			}
+			rest, _ := filepath.Rel(prefix, relPath)
+			return filepath.Join(target, rest)
+		}
+	}
+	return filepath.Join(loc.startDir, relPath)
+}
+
+/**
+ * PanelTotals: Возвращает количество и площадь панелей в папке по её пути относительно стартовой папки.
+ * Для заказов, упакованных в zip-архив, используются итоги, запомненные перед упаковкой.
+ */
+func (loc FolderLocations) PanelTotals(relPath string) (int, float64) {
+	if totals, ok := loc.totals[relPath]; ok {
+		return totals.panels, totals.area
+	}
+	return panel.FolderPanelTotals(loc.Locate(relPath))
+}
+
+/**
+ * archiveTargetPath: Формирует путь заказа в архиве относительно TargetDir по шаблону.
+ * Пустые части пути (например, {customer} без уровня заказчика) пропускаются.
+ * @param template - Шаблон пути (проверенный validateArchivePath).
+ * @param ref - Заказ.
+ * @param ready - Дата готовности заказа.
+ * @return string - Относительный путь.
+ */
+func archiveTargetPath(template string, ref walker.OrderRef, ready time.Time) string {
+	weekYear, week := ready.ISOWeek()
+	values := map[string]string{
+		"year":     ready.Format("2006"),
+		"month":    ready.Format("01"),
+		"day":      ready.Format("02"),
+		"week":     fmt.Sprintf("%02d", week),
+		"weekyear": fmt.Sprintf("%04d", weekYear),
+		"customer": ref.AncestorName(config.LevelCustomer),
+		"project":  ref.AncestorName(config.LevelProject),
+		"order":    ref.Item.ItemName,
+		"path":     ref.RelPath(),
+	}
+	return config.ExpandArchivePath(template, values)
+}
+
+/**
+ * collectListReferences: Собирает пути к файлам-заданиям из всех списков работ (list.xml и списков
+ * станков из Machines) в стартовой папке.
+ * Игнорируемые папки не просматриваются.
+ * @param startDir - Стартовая папка обхода.
+ * @param settings - Настройки программы.
+ * @return []listReference - Ссылки на файлы-задания.
+ */
+func collectListReferences(startDir string, settings config.Settings) []listReference {
+	var result []listReference
+	fileio.WalkDir(startDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != startDir && fileio.HasStringInList(d.Name(), settings.IgnoreList) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		machine, isList := settings.MachineByListFile(d.Name())
+		if !isList {
+			return nil
+		}
+		data, errRead := fileio.ReadFile(path)
+		if errRead != nil {
+			return nil
+		}
+		filePaths, errParse := worklist.ReadFilePaths(machine.Format, data)
+		if errParse != nil {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Не удалось разобрать %s при проверке ссылок на заказы"), path), logging.FieldPath, path, logging.FieldAction, "check-references")
+			return nil
+		}
+		for _, filePath := range filePaths {
+			// путь записан так, как его видит станок (см. ListPaths)
+			result = append(result, listReference{listPath: path, filePath: machine.ListPaths.Resolve(filePath, filepath.Dir(path))})
+		}
+		return nil
+	})
+	return result
+}
+
+/**
+ * findReferencingList: Ищет list.xml вне папки заказа, который ссылается на файлы внутри неё.
+ * Пути сравниваются без учёта регистра, как в Windows.
+ * @param orderDir - Полный путь к папке заказа.
+ * @param refs - Ссылки из всех list.xml.
+ * @return string - Путь к ссылающемуся list.xml или пустая строка.
+ */
+func findReferencingList(orderDir string, refs []listReference) string {
+	normalize := func(path string) string {
+		if absPath, err := filepath.Abs(path); err == nil {
+			path = absPath
+		}
+		return strings.ToLower(path)
+	}
+	prefix := normalize(orderDir) + string(filepath.Separator)
+	for _, ref := range refs {
+		if strings.HasPrefix(normalize(ref.listPath), prefix) {
+			continue
+		}
+		if strings.HasPrefix(normalize(ref.filePath), prefix) {
+			return ref.listPath
+		}
+	}
+	return ""
+}
+
+/**
+ * MoveReadyOrders: Перемещает готовые заказы в TargetDir по шаблону пути архива
+ * (по умолчанию TargetDir/yyyy-mm/<вышестоящие папки>/<заказ>). Заказ остаётся на месте,
+ * если с даты готовности прошло меньше GraceDays дней или на его файлы ссылается list.xml
+ * из другой папки. Папки, в которых после перемещения остались только метки о выполнении, удаляются.
+ * Перемещение не работает при открытом окне Проводника.
+ * @param startDir - Стартовая папка обхода.
+ * @param reports - Отчёты по папкам верхнего уровня с проставленными уровнями иерархии.
+ * @param settings - Настройки программы.
+ * @return FolderLocations - Расположение папок после перемещения.
+ */
+func MoveReadyOrders(startDir string, reports []walker.ReportObj, settings config.Settings) FolderLocations {
+	locations := FolderLocations{startDir: startDir, moved: make(map[string]string), totals: make(map[string]panelTotals)}
+	var refs []listReference
+	refsCollected := false
+	today := time.Now()
+	for _, ref := range walker.CollectOrders(reports) {
+		if ref.Item.Status != walker.StatusReady {
+			continue
+		}
+		relPath := ref.RelPath()
+		ready, err := time.ParseInLocation(time.DateOnly, ref.Item.DateReady, time.Local)
+		if err != nil {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Некорректная дата готовности %q у заказа %s"), ref.Item.DateReady, relPath), logging.FieldPath, relPath, logging.FieldAction, "archive")
+			continue
+		}
+		if days := int(today.Sub(ready).Hours() / 24); days < settings.ArchiveGrace {
+			logging.Info(fmt.Sprintf(i18n.Tr("Заказ %s готов %d дн. назад, будет перемещён в архив через %d дн."), relPath, days, settings.ArchiveGrace-days),
+				logging.FieldPath, relPath, logging.FieldAction, "archive-wait")
+			continue
+		}
+		sourcePath := filepath.Join(startDir, relPath)
+		if !refsCollected {
+			refs = collectListReferences(startDir, settings)
+			refsCollected = true
+		}
+		if listPath := findReferencingList(sourcePath, refs); listPath != "" {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не перемещён в архив: на его файлы ссылается %s"), relPath, listPath),
+				logging.FieldPath, relPath, logging.FieldAction, "archive-skip", "list", listPath)
+			continue
+		}
+
+		targetPath := filepath.Join(settings.DirTarget, archiveTargetPath(settings.ArchivePath, ref, ready))
+		targetParent := filepath.Dir(targetPath)
+		if !fileio.IsValidDir(targetParent) {
+			fileio.MkdirAll(targetParent, 0777)
+			if !fileio.IsValidDir(targetParent) {
+				logging.Error(fmt.Sprintf(i18n.Tr("Папка %s всё ещё недоступна"), targetParent), logging.FieldPath, targetParent, logging.FieldAction, "mkdir")
+			}
+		}
+		if settings.ArchiveCompress {
+			targetPath += ".zip"
+		}
+		if _, err := fileio.Stat(targetPath); err == nil {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не перемещён в архив: %s уже существует"), relPath, targetPath),
+				logging.FieldPath, relPath, logging.FieldAction, "archive-skip", "target", targetPath)
+			continue
+		}
+		if settings.ArchiveCompress {
+			collectPanelTotals(sourcePath, relPath, locations.totals)
+			if err := zipOrder(sourcePath, targetPath, relPath, ref.Item.DateReady); err != nil {
+				// перемещение не записывается и при созданном архиве: папка заказа удалена не полностью
+				logging.Error(fmt.Sprintf(i18n.Tr("Ошибка упаковки заказа %s: %v"), relPath, err), logging.FieldPath, relPath, logging.FieldAction, "archive-zip", logging.FieldError, err)
+				continue
+			}
+			logging.Info(fmt.Sprintf(i18n.Tr("Заказ %s упакован в %s"), relPath, targetPath), logging.FieldPath, relPath, logging.FieldAction, "archive-zip", "target", targetPath)
+			locations.moved[relPath] = targetPath
+			removeEmptyParents(startDir, filepath.Dir(relPath))
+			continue
+		}
+		err0 := fileio.Rename(sourcePath, targetPath)
+		if err0 != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Ошибка перемещения директории %s: %v\n\nЗакройте окно Проводника!"), relPath, err0),
+				logging.FieldPath, relPath, logging.FieldAction, "archive-move", logging.FieldError, err0)
+			continue
+		}
+		logging.Info(fmt.Sprintf(i18n.Tr("Заказ %s перемещён в %s"), relPath, targetPath), logging.FieldPath, relPath, logging.FieldAction, "archive-move", "target", targetPath)
+		locations.moved[relPath] = targetPath
+		removeEmptyParents(startDir, filepath.Dir(relPath))
+	}
+	return locations
+}
+
+/**
+ * removeEmptyParents: Удаляет папки, в которых не осталось ничего, кроме меток order_ready,
+ * поднимаясь от указанной папки до стартовой (сама стартовая папка не удаляется).
+ * @param startDir - Стартовая папка обхода.
+ * @param relDir - Путь к папке относительно стартовой.
+ */
+func removeEmptyParents(startDir string, relDir string) {
+	for ; relDir != "." && relDir != ""; relDir = filepath.Dir(relDir) {
+		dirPath := filepath.Join(startDir, relDir)
+		dirEntries, err := fileio.ReadDir(dirPath)
+		if err != nil {
+			return
+		}
+		for _, entry := range dirEntries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), "order_ready_") {
+				return
+			}
+		}
+		for _, entry := range dirEntries {
+			fileio.Remove(filepath.Join(dirPath, entry.Name()))
+		}
+		if err := fileio.Remove(dirPath); err != nil {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Не удалось удалить опустевшую папку %s: %v"), dirPath, err), logging.FieldPath, dirPath, logging.FieldAction, "remove-empty", logging.FieldError, err)
+			return
+		}
+	}
+}
+
+/**
+ * FindArchivedOrders: Ищет в архиве заказы - верхние папки с меткой order_ready и zip-архивы заказов.
+ * Расположение заказов задаётся шаблоном пути архива (например, yyyy-mm/заказчик/заказ).
+ * @param archiveDir - Архивная папка (TargetDir).
+ * @param relDir - Путь к осматриваемой папке относительно архивной.
+ * @return []string - Пути к папкам и zip-файлам заказов относительно архивной папки.
+ */
+func FindArchivedOrders(archiveDir string, relDir string) []string {
+	dirEntries, err := fileio.ReadDir(filepath.Join(archiveDir, relDir))
+	if err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Не удалось прочитать папку %s: %v"), filepath.Join(archiveDir, relDir), err),
+			logging.FieldPath, filepath.Join(archiveDir, relDir), logging.FieldAction, "find-archived", logging.FieldError, err)
+		return nil
+	}
+	var result []string
+	for _, entry := range dirEntries {
+		entryRel := filepath.Join(relDir, entry.Name())
+		if !entry.IsDir() {
+			if fileio.GetExtension(entry.Name()) == "zip" {
+				result = append(result, entryRel)
+			}
+			continue
+		}
+		if walker.FindOrderMarker(filepath.Join(archiveDir, entryRel)) != "" {
+			result = append(result, entryRel)
+		} else {
+			result = append(result, FindArchivedOrders(archiveDir, entryRel)...)
+		}
+	}
+	return result
+}
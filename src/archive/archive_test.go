@@ -0,0 +1,105 @@
+package archive_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+	"github.com/ProOwler/ListMaker/fixture/fixturetest"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Строит в памяти папку /work с настройками (extra - дополнительные элементы файла настроек) и пустой TargetDir,
+// возвращает дерево SourceDir и настройки
+func newArchiveTree(t *testing.T, extra ...string) (fixture.Tree, config.Settings) {
+	t.Helper()
+	fixturetest.Mem(t)
+	work := fixture.New("/work")
+	var settings config.Settings
+	if err := settings.ReadFromFile(work.Settings("listMaker_settings.xml", "./src", "./done", extra...)); err != nil {
+		t.Fatal(err)
+	}
+	fixture.New(settings.DirTarget)
+	return fixture.New(settings.DirSource), settings
+}
+
+// Обходит SourceDir, проставляет уровни иерархии и перемещает готовые заказы в архив
+func walkAndMove(settings config.Settings) archive.FolderLocations {
+	reports := walker.Walk(settings.DirSource, settings).InnerItems
+	for i := range reports {
+		reports[i].AssignLevels(1, &settings)
+	}
+	return archive.MoveReadyOrders(settings.DirSource, reports, settings)
+}
+
+// Проверяет наличие папки или файла
+func checkExists(t *testing.T, path string, want bool) {
+	t.Helper()
+	if _, err := fileio.Stat(path); (err == nil) != want {
+		t.Errorf("%s: существует = %t; want %t", path, err == nil, want)
+	}
+}
+
+// Заказ перемещается по шаблону пути архива с подстановками заказчика, года и заказа
+func TestMoveReadyOrdersPathTemplate(t *testing.T) {
+	// Arrange
+	tree, settings := newArchiveTree(t,
+		`<Hierarchy><Level Kind="customer" Depth="1"/><Level Kind="order" Depth="2"/><Level Kind="material"/></Hierarchy>`,
+		`<Archive PathTemplate="{customer}/{year}/{order}"/>`)
+	tree.Panel("Иванов/Кухня/ЛДСП/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Иванов/Кухня/ЛДСП", "20250601")
+	tree.Panel("Иванов/Шкаф/ЛДСП/1_2_Бок.xml", 2000, 400, 2)
+	// Action
+	locations := walkAndMove(settings)
+	// Assert
+	target := filepath.Join(settings.DirTarget, "Иванов", "2025", "Кухня")
+	checkExists(t, filepath.Join(target, "ЛДСП", "1_2_Бок.xml"), true)
+	checkExists(t, tree.Path("Иванов/Кухня"), false)
+	checkExists(t, tree.Path("Иванов/Шкаф/ЛДСП/1_2_Бок.xml"), true)
+	if got := locations.Locate(filepath.Join("Иванов", "Кухня", "ЛДСП")); got != filepath.Join(target, "ЛДСП") {
+		t.Errorf("Locate: %s; want %s", got, filepath.Join(target, "ЛДСП"))
+	}
+}
+
+// Заказ, готовый меньше GraceDays дней назад, остаётся в SourceDir, более старый перемещается
+func TestMoveReadyOrdersGraceDays(t *testing.T) {
+	// Arrange
+	tree, settings := newArchiveTree(t, `<Archive PathTemplate="{order}" GraceDays="7"/>`)
+	tree.Panel("Свежий/Кухня/ЛДСП/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Свежий/Кухня/ЛДСП", time.Now().AddDate(0, 0, -2).Format("20060102"))
+	tree.Panel("Старый/Кухня/ЛДСП/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Старый/Кухня/ЛДСП", time.Now().AddDate(0, 0, -30).Format("20060102"))
+	// Action
+	locations := walkAndMove(settings)
+	// Assert
+	checkExists(t, tree.Path("Свежий/Кухня/ЛДСП/1_2_Бок.xml"), true)
+	checkExists(t, filepath.Join(settings.DirTarget, "Свежий"), false)
+	checkExists(t, tree.Path("Старый"), false)
+	checkExists(t, filepath.Join(settings.DirTarget, "Старый", "Кухня", "ЛДСП", "1_2_Бок.xml"), true)
+	if got := locations.Locate("Свежий"); got != tree.Path("Свежий") {
+		t.Errorf("Locate: %s; want %s", got, tree.Path("Свежий"))
+	}
+}
+
+// Заказ, на файлы которого ссылается list.xml из другой папки, остаётся в SourceDir
+func TestMoveReadyOrdersReferencedByList(t *testing.T) {
+	// Arrange
+	tree, settings := newArchiveTree(t)
+	tree.Panel("Иванов/Кухня/ЛДСП/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Иванов/Кухня/ЛДСП", "20250601")
+	tree.Panel("Петров/Шкаф/ЛДСП/3_1_Полка.xml", 600, 300, 1)
+	tree.List("Петров/Шкаф/ЛДСП", "3_1_Полка.xml", "../../../Иванов/Кухня/ЛДСП/1_2_Бок.xml")
+	tree.Panel("Сидоров/Прихожая/ЛДСП/1_1_Дно.xml", 800, 500, 1)
+	tree.Ready("Сидоров/Прихожая/ЛДСП", "20250602")
+	// Action
+	walkAndMove(settings)
+	// Assert
+	checkExists(t, tree.Path("Иванов/Кухня/ЛДСП/1_2_Бок.xml"), true)
+	checkExists(t, filepath.Join(settings.DirTarget, "2025-06", "Иванов"), false)
+	checkExists(t, tree.Path("Сидоров"), false)
+	checkExists(t, filepath.Join(settings.DirTarget, "2025-06", "Сидоров", "Прихожая", "ЛДСП", "1_1_Дно.xml"), true)
+}
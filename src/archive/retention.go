@@ -0,0 +1,289 @@
+package archive
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// папки месяцев в архиве при шаблоне пути по умолчанию: yyyy-mm
+var monthDirName = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+
+// формат даты и времени в начале имён отчётов о работе
+const reportTimeLayout = "2006-01-02_15-04-05"
+
+// Проверяет, что правило задано и возраст его превышает
+func olderThan(age int, months int) bool {
+	return months > 0 && age > months
+}
+
+/**
+ * Cleanup: Применяет правила хранения к TargetDir.
+ * Заказы ищутся по всему архиву (FindArchivedOrders), поэтому правила работают при любом шаблоне ArchivePath;
+ * возраст заказа - число месяцев от месяца его готовности (DateReady метки или описи zip-архива) до текущего.
+ * Заказы старше DeleteAfterMonths удаляются, старше SummaryAfterMonths - от них остаются
+ * только метки order_ready, старше CompressAfterMonths - заказы упаковываются в zip-архивы.
+ * Отчёты о работе старше ReportsKeepDays удаляются, последний XML-отчёт сохраняется для сравнения.
+ * @param settings - Настройки программы (правила хранения).
+ * @param dryRun - true, если нужно только вывести, что было бы сделано.
+ * @return error - Ошибка чтения TargetDir.
+ */
+func Cleanup(settings config.Settings, dryRun bool) error {
+	policy := settings.Retention
+	now := time.Now()
+
+	if _, err := fileio.ReadDir(settings.DirTarget); err != nil {
+		return fmt.Errorf(i18n.Tr("Не удалось прочитать папку %s: %v"), settings.DirTarget, err)
+	}
+	for _, orderRel := range FindArchivedOrders(settings.DirTarget, ".") {
+		orderPath := filepath.Join(settings.DirTarget, orderRel)
+		ready, err := archivedOrderDate(orderPath)
+		if err != nil {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Не удалось определить дату готовности заказа %s: %v"), orderPath, err), logging.FieldPath, orderPath, logging.FieldAction, "cleanup", logging.FieldError, err)
+			continue
+		}
+		age := (now.Year()*12 + int(now.Month())) - (ready.Year()*12 + int(ready.Month()))
+		switch {
+		case olderThan(age, policy.DeleteAfter):
+			deleteArchivedOrder(orderPath, settings.DirTarget, age, dryRun)
+		case olderThan(age, policy.SummaryAfter):
+			summarizeOrder(orderPath, dryRun)
+		case olderThan(age, policy.CompressAfter):
+			compressArchivedOrder(orderPath, sourceRelPath(orderRel), dryRun)
+		}
+	}
+	if policy.ReportsDays > 0 {
+		pruneWorkReports(settings, now.AddDate(0, 0, -policy.ReportsDays), dryRun)
+	}
+	return nil
+}
+
+/**
+ * archivedOrderDate: Возвращает дату готовности архивного заказа.
+ * @param orderPath - Полный путь к папке заказа (дата из имени метки order_ready) или к zip-архиву (дата из описи).
+ * @return time.Time - Дата готовности.
+ * @return error - Нет метки или описи, в них нет корректной даты.
+ */
+func archivedOrderDate(orderPath string) (time.Time, error) {
+	var dateReady string
+	if fileio.GetExtension(orderPath) == "zip" {
+		zipReader, err := fileio.OpenZip(orderPath)
+		if err != nil {
+			return time.Time{}, err
+		}
+		manifest, err := ReadZipManifest(zipReader)
+		if err != nil {
+			return time.Time{}, err
+		}
+		dateReady = manifest.DateReady
+	} else {
+		markerPath := walker.FindOrderMarker(orderPath)
+		if markerPath == "" {
+			return time.Time{}, fmt.Errorf(i18n.Tr("в папке %s нет метки о выполнении"), orderPath)
+		}
+		var err error
+		if dateReady, err = panel.GetReadyDate(filepath.Base(markerPath)); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.ParseInLocation(time.DateOnly, dateReady, time.Local)
+}
+
+/**
+ * sourceRelPath: Восстанавливает путь к заказу от стартовой папки по его пути в архиве - для описи
+ * упаковываемого заказа. При шаблоне по умолчанию ({year}-{month}/{path}) отбрасывается папка месяца,
+ * при другом шаблоне исходный путь неизвестен и записывается путь в архиве.
+ * @param orderRel - Путь к заказу относительно TargetDir.
+ * @return string - Путь к заказу для восстановления.
+ */
+func sourceRelPath(orderRel string) string {
+	parts := strings.SplitN(filepath.ToSlash(orderRel), "/", 2)
+	if len(parts) == 2 && monthDirName.MatchString(parts[0]) {
+		return filepath.FromSlash(parts[1])
+	}
+	return orderRel
+}
+
+/**
+ * deleteArchivedOrder: Удаляет архивный заказ (папку или zip-архив) и ставшие пустыми папки над ним.
+ * @param orderPath - Полный путь к папке или zip-архиву заказа.
+ * @param archiveDir - Архивная папка (TargetDir), выше неё папки не удаляются.
+ * @param age - Возраст заказа в месяцах (для журнала).
+ * @param dryRun - true, если изменения вносить не нужно.
+ */
+func deleteArchivedOrder(orderPath string, archiveDir string, age int, dryRun bool) {
+	logging.Info(fmt.Sprintf(i18n.Tr("Удаление заказа %s (%d мес.)"), orderPath, age), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-delete")
+	if dryRun {
+		return
+	}
+	if err := fileio.RemoveAll(orderPath); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка удаления заказа %s: %v"), orderPath, err), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-delete", logging.FieldError, err)
+		return
+	}
+	for dir := filepath.Dir(orderPath); dir != archiveDir && strings.HasPrefix(dir, archiveDir); dir = filepath.Dir(dir) {
+		if dirEntries, err := fileio.ReadDir(dir); err != nil || len(dirEntries) > 0 || fileio.Remove(dir) != nil {
+			break
+		}
+	}
+}
+
+/**
+ * compressArchivedOrder: Упаковывает папку архивного заказа в zip-архив рядом с ней.
+ * @param orderPath - Полный путь к папке заказа (zip-архивы пропускаются).
+ * @param relPath - Путь к заказу от стартовой папки (см. sourceRelPath), сохраняется в описи для восстановления.
+ * @param dryRun - true, если изменения вносить не нужно.
+ */
+func compressArchivedOrder(orderPath string, relPath string, dryRun bool) {
+	if fileio.GetExtension(orderPath) == "zip" {
+		return
+	}
+	markerPath := walker.FindOrderMarker(orderPath)
+	order, err := walker.ReadOrderMarker(markerPath)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не упакован: %v"), orderPath, err), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-zip", logging.FieldError, err)
+		return
+	}
+	if _, err := fileio.Stat(orderPath + ".zip"); err == nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не упакован: %s.zip уже существует"), orderPath, orderPath), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-zip")
+		return
+	}
+	logging.Info(fmt.Sprintf(i18n.Tr("Упаковка заказа %s"), orderPath), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-zip")
+	if dryRun {
+		return
+	}
+	if err := zipOrder(orderPath, orderPath+".zip", relPath, order.DateReady); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка упаковки заказа %s: %v"), orderPath, err), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-zip", logging.FieldError, err)
+	}
+}
+
+/**
+ * summarizeOrder: Оставляет от архивного заказа только метку order_ready - сводку о нём.
+ * Метка остаётся в папке заказа, поэтому заказ по-прежнему учитывается в статистике
+ * (без раскроя по материалам и срока выполнения). Zip-архив заменяется папкой с меткой.
+ * @param orderPath - Полный путь к папке или zip-архиву заказа.
+ * @param dryRun - true, если изменения вносить не нужно.
+ */
+func summarizeOrder(orderPath string, dryRun bool) {
+	if fileio.GetExtension(orderPath) == "zip" {
+		summarizeZipOrder(orderPath, dryRun)
+		return
+	}
+	markerPath := walker.FindOrderMarker(orderPath)
+	dirEntries, err := fileio.ReadDir(orderPath)
+	if err != nil || len(dirEntries) == 1 {
+		return
+	}
+	logging.Info(fmt.Sprintf(i18n.Tr("Сокращение заказа %s до метки %s"), orderPath, filepath.Base(markerPath)), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-summary")
+	if dryRun {
+		return
+	}
+	for _, entry := range dirEntries {
+		entryPath := filepath.Join(orderPath, entry.Name())
+		if entryPath == markerPath {
+			continue
+		}
+		if err := fileio.RemoveAll(entryPath); err != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Ошибка удаления %s: %v"), entryPath, err), logging.FieldPath, entryPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+		}
+	}
+}
+
+// Заменяет zip-архив заказа папкой с одной меткой order_ready
+func summarizeZipOrder(zipPath string, dryRun bool) {
+	zipReader, err := fileio.OpenZip(zipPath)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не сокращён: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+		return
+	}
+	manifest, err := ReadZipManifest(zipReader)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не сокращён: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+		return
+	}
+	markerData, err := ReadZipEntry(zipReader, manifest.Marker)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не сокращён: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+		return
+	}
+	orderPath := strings.TrimSuffix(zipPath, filepath.Ext(zipPath))
+	if _, err := fileio.Stat(orderPath); err == nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не сокращён: %s уже существует"), zipPath, orderPath), logging.FieldPath, zipPath, logging.FieldAction, "cleanup-summary")
+		return
+	}
+	markerName := filepath.Base(manifest.Marker)
+	logging.Info(fmt.Sprintf(i18n.Tr("Сокращение заказа %s до метки %s"), zipPath, markerName), logging.FieldPath, zipPath, logging.FieldAction, "cleanup-summary")
+	if dryRun {
+		return
+	}
+	if err := fileio.MkdirAll(orderPath, 0777); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка создания папки %s: %v"), orderPath, err), logging.FieldPath, orderPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+		return
+	}
+	markerPath := filepath.Join(orderPath, markerName)
+	if err := fileio.CreateFile(markerPath, markerData); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка записи метки %s: %v"), markerPath, err), logging.FieldPath, markerPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+		return
+	}
+	if entry, err := zipReader.Open(manifest.Marker); err == nil {
+		if info, err := entry.Stat(); err == nil {
+			fileio.Chtimes(markerPath, info.ModTime())
+		}
+		entry.Close()
+	}
+	if err := fileio.Remove(zipPath); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка удаления архива %s: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "cleanup-summary", logging.FieldError, err)
+	}
+}
+
+/**
+ * pruneWorkReports: Удаляет отчёты о работе (*_WorkReport.*) старше указанного момента.
+ * Время отчёта берётся из начала имени файла; последний XML-отчёт не удаляется,
+ * чтобы следующему запуску было с чем сравнить.
+ * @param settings - Настройки программы (TargetDir и имя отчёта).
+ * @param before - Отчёты, созданные раньше этого момента, удаляются.
+ * @param dryRun - true, если изменения вносить не нужно.
+ */
+func pruneWorkReports(settings config.Settings, before time.Time, dryRun bool) {
+	keep := ""
+	if saved := walker.FindSavedReports(settings); len(saved) > 0 {
+		keep = saved[len(saved)-1]
+	}
+	baseName := strings.TrimSuffix(settings.FileReport, filepath.Ext(settings.FileReport))
+	files, err := filepath.Glob(filepath.Join(settings.DirTarget, "*_"+baseName+"*"))
+	if err != nil {
+		return
+	}
+	removed := 0
+	for _, file := range files {
+		name := filepath.Base(file)
+		if file == keep || len(name) < len(reportTimeLayout) {
+			continue
+		}
+		created, err := time.ParseInLocation(reportTimeLayout, name[:len(reportTimeLayout)], time.Local)
+		if err != nil || !created.Before(before) {
+			continue
+		}
+		removed++
+		if dryRun {
+			continue
+		}
+		if err := fileio.Remove(file); err != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Ошибка удаления отчёта %s: %v"), file, err), logging.FieldPath, file, logging.FieldAction, "cleanup-reports", logging.FieldError, err)
+			removed--
+		}
+	}
+	if dryRun {
+		logging.Info(fmt.Sprintf(i18n.Tr("Будет удалено отчётов о работе старше %s: %d"), before.Format(time.DateOnly), removed), logging.FieldAction, "cleanup-reports")
+	} else {
+		logging.Info(fmt.Sprintf(i18n.Tr("Удалено отчётов о работе старше %s: %d"), before.Format(time.DateOnly), removed), logging.FieldAction, "cleanup-reports")
+	}
+}
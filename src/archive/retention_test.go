@@ -0,0 +1,55 @@
+package archive_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+	"github.com/ProOwler/ListMaker/fixture/fixturetest"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Правила хранения применяются к заказам и без папок месяцев yyyy-mm (другой шаблон ArchivePath)
+func TestCleanupCustomArchivePath(t *testing.T) {
+	// Arrange
+	fixturetest.Mem(t)
+	work := fixture.New("/work")
+	var settings config.Settings
+	if err := settings.ReadFromFile(work.Settings("listMaker_settings.xml", "./src", "./done",
+		`<Retention CompressAfterMonths="0" SummaryAfterMonths="24" DeleteAfterMonths="60" ReportsKeepDays="0"/>`)); err != nil {
+		t.Fatal(err)
+	}
+	done := fixture.New(settings.DirTarget)
+	now := time.Now()
+	for rel, ready := range map[string]time.Time{
+		"Заказчики/Иванов/Старый":  now.AddDate(-6, 0, 0),
+		"Заказчики/Петров/Средний": now.AddDate(-3, 0, 0),
+		"Заказчики/Петров/Свежий":  now.AddDate(0, -1, 0),
+	} {
+		done.Panel(rel+"/Кухня/1_1_Бок.xml", 700, 400, 1)
+		done.OrderMarker(rel, walker.ReportObj{ItemName: "Кухня", DateReady: ready.Format(time.DateOnly), Status: walker.StatusReady})
+	}
+	// Action
+	err := archive.Cleanup(settings, false)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rel, want := range map[string]bool{
+		"Заказчики/Иванов":                          false,
+		"Заказчики/Петров/Средний/Кухня":            false,
+		"Заказчики/Петров/Свежий/Кухня":             true,
+		"Заказчики/Петров/Средний":                  true,
+		"Заказчики/Петров/Свежий/Кухня/1_1_Бок.xml": true,
+	} {
+		if _, err := fileio.Stat(done.Path(rel)); (err == nil) != want {
+			t.Errorf("%s: существует = %t; want %t", rel, err == nil, want)
+		}
+	}
+	if got := archive.FindArchivedOrders(settings.DirTarget, "."); len(got) != 2 {
+		t.Errorf("заказы после очистки: %v; want 2", got)
+	}
+}
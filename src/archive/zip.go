@@ -0,0 +1,402 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Имя файла описи в zip-архиве заказа
+const ZipManifestName = "manifest.xml"
+
+// Версия формата описи zip-архива
+const c_MANIFEST_VERSION = "1"
+
+// Суффикс остатка папки заказа, которую после упаковки не удалось удалить полностью
+const c_ZIPPED_REMAINDER_SUFFIX = ".zipped"
+
+// XZipManifest: Опись zip-архива заказа
+type XZipManifest struct {
+	XMLName    xml.Name   `xml:"Manifest"`
+	Version    string     `xml:"Version,attr"`
+	Generator  string     `xml:"Generator,attr"`
+	Created    string     `xml:"Created,attr"`
+	Order      string     `xml:"Order,attr"`      // Имя папки заказа
+	SourcePath string     `xml:"SourcePath,attr"` // Путь к заказу от стартовой папки на момент архивации
+	DateReady  string     `xml:"DateReady,attr"`  // Дата готовности заказа
+	Marker     string     `xml:"Marker,attr"`     // Путь к метке order_ready внутри архива
+	File       []XZipFile `xml:"File"`
+}
+
+// XZipFile: Файл заказа в описи
+type XZipFile struct {
+	Path   string `xml:"Path,attr"` // Путь внутри архива
+	Size   int64  `xml:"Size,attr"`
+	SHA256 string `xml:"SHA256,attr"`
+}
+
+// Суммарное количество и площадь панелей в папке
+type panelTotals struct {
+	panels int
+	area   float64
+}
+
+/**
+ * zipOrder: Упаковывает папку готового заказа в zip-архив с описью файлов.
+ * Архив пишется во временный файл, проверяется по описи и только затем получает своё имя;
+ * исходная папка удаляется после успешной проверки; остаток не до конца удалённой папки
+ * убирается в сторону (см. setZippedRemainderAside), а zipOrder возвращает ошибку.
+ * @param sourcePath - Полный путь к папке заказа.
+ * @param zipPath - Полный путь к создаваемому zip-файлу.
+ * @param relPath - Путь к заказу от стартовой папки, по нему заказ восстанавливается.
+ * @param dateReady - Дата готовности заказа.
+ * @return error - Ошибка упаковки, проверки или удаления исходной папки.
+ */
+func zipOrder(sourcePath string, zipPath string, relPath string, dateReady string) error {
+	orderName := filepath.Base(sourcePath)
+	markerPath := walker.FindOrderMarker(sourcePath)
+	if markerPath == "" {
+		return fmt.Errorf(i18n.Tr("в папке %s нет метки о выполнении"), sourcePath)
+	}
+	manifest := XZipManifest{
+		Version:    c_MANIFEST_VERSION,
+		Generator:  walker.ReportGenerator,
+		Created:    time.Now().Format(time.DateTime),
+		Order:      orderName,
+		SourcePath: filepath.ToSlash(relPath),
+		DateReady:  dateReady,
+		Marker:     path.Join(orderName, filepath.Base(markerPath)),
+	}
+
+	tmpPath := zipPath + ".tmp"
+	var out bytes.Buffer
+	zipWriter := zip.NewWriter(&out)
+	errWalk := fileio.WalkDir(sourcePath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(sourcePath, filePath)
+		name := path.Join(orderName, filepath.ToSlash(rel))
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// папки сохраняются отдельными записями, чтобы восстановить и пустые
+			header.Name = name + "/"
+			_, err = zipWriter.CreateHeader(header)
+			return err
+		}
+		header.Name = name
+		header.Method = zip.Deflate
+		entry, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		data, err := fileio.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(data)
+		if _, err := entry.Write(data); err != nil {
+			return err
+		}
+		size := int64(len(data))
+		manifest.File = append(manifest.File, XZipFile{Path: name, Size: size, SHA256: hex.EncodeToString(hash[:])})
+		return nil
+	})
+	if errWalk == nil {
+		errWalk = writeZipManifest(zipWriter, manifest)
+	}
+	if errClose := zipWriter.Close(); errWalk == nil {
+		errWalk = errClose
+	}
+	if errWalk == nil {
+		if errWalk = fileio.WriteFile(tmpPath, out.Bytes(), 0644); errWalk != nil {
+			errWalk = fmt.Errorf(i18n.Tr("не удалось создать архив %s: %w"), tmpPath, errWalk)
+		} else {
+			errWalk = verifyZipFile(tmpPath)
+		}
+	}
+	if errWalk != nil {
+		fileio.Remove(tmpPath)
+		return fmt.Errorf(i18n.Tr("не удалось упаковать заказ %s: %w"), sourcePath, errWalk)
+	}
+	if err := fileio.Rename(tmpPath, zipPath); err != nil {
+		fileio.Remove(tmpPath)
+		return fmt.Errorf(i18n.Tr("не удалось сохранить архив %s: %w"), zipPath, err)
+	}
+	if err := fileio.RemoveAll(sourcePath); err != nil {
+		remainder := setZippedRemainderAside(sourcePath)
+		return fmt.Errorf(i18n.Tr("архив %s создан, но папку заказа не удалось удалить полностью, остаток - %s: %w\n\nЗакройте окно Проводника и удалите остаток!"), zipPath, remainder, err)
+	}
+	return nil
+}
+
+/**
+ * setZippedRemainderAside: Убирает остаток не до конца удалённой папки упакованного заказа:
+ * папка переименовывается в <заказ>.zipped, а метка order_ready из неё удаляется, чтобы остаток
+ * не был снова принят за готовый заказ. Заказ уже сохранён в zip-архиве, остаток удаляет пользователь.
+ * @param sourcePath - Полный путь к папке заказа.
+ * @return string - Путь к остатку (прежний, если переименовать не удалось).
+ */
+func setZippedRemainderAside(sourcePath string) string {
+	remainder := sourcePath + c_ZIPPED_REMAINDER_SUFFIX
+	if fileio.Rename(sourcePath, remainder) != nil {
+		remainder = sourcePath
+	}
+	if markerPath := walker.FindOrderMarker(remainder); markerPath != "" {
+		fileio.Remove(markerPath)
+	}
+	return remainder
+}
+
+// Записывает опись в zip-архив
+func writeZipManifest(zipWriter *zip.Writer, manifest XZipManifest) error {
+	data, err := xml.MarshalIndent(manifest, "", "	")
+	if err != nil {
+		return err
+	}
+	entry, err := zipWriter.CreateHeader(&zip.FileHeader{Name: ZipManifestName, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write([]byte(`<?xml version="1.0" encoding="utf-8" ?>` + "\n" + string(data)))
+	return err
+}
+
+/**
+ * ReadZipManifest: Читает опись zip-архива заказа.
+ * @return XZipManifest - Опись.
+ * @return error - Ошибка, если описи нет или она повреждена.
+ */
+func ReadZipManifest(zipReader *zip.Reader) (XZipManifest, error) {
+	var manifest XZipManifest
+	data, err := ReadZipEntry(zipReader, ZipManifestName)
+	if err != nil {
+		return manifest, err
+	}
+	if err := xml.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf(i18n.Tr("не удалось разобрать опись архива: %w"), err)
+	}
+	if manifest.Version != c_MANIFEST_VERSION {
+		return manifest, fmt.Errorf(i18n.Tr("неподдерживаемая версия описи архива %q"), manifest.Version)
+	}
+	return manifest, nil
+}
+
+// Читает содержимое файла из zip-архива по имени
+func ReadZipEntry(zipReader *zip.Reader, name string) ([]byte, error) {
+	entry, err := zipReader.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.Tr("в архиве нет файла %s: %w"), name, err)
+	}
+	defer entry.Close()
+	return io.ReadAll(entry)
+}
+
+/**
+ * verifyZipArchive: Сверяет содержимое zip-архива с описью: размеры и контрольные суммы файлов,
+ * наличие метки order_ready и отсутствие путей, выходящих за папку заказа.
+ * @return XZipManifest - Опись проверенного архива.
+ * @return error - Описание первого найденного расхождения.
+ */
+func verifyZipArchive(zipReader *zip.Reader) (XZipManifest, error) {
+	manifest, err := ReadZipManifest(zipReader)
+	if err != nil {
+		return manifest, err
+	}
+	if manifest.Order == "" || strings.ContainsAny(manifest.Order, `/\`) || manifest.Order == ".." {
+		return manifest, fmt.Errorf(i18n.Tr("некорректное имя заказа %q в описи"), manifest.Order)
+	}
+	listed := make(map[string]bool)
+	for _, file := range manifest.File {
+		if !strings.HasPrefix(file.Path, manifest.Order+"/") || !fs.ValidPath(file.Path) {
+			return manifest, fmt.Errorf(i18n.Tr("некорректный путь %q в описи"), file.Path)
+		}
+		listed[file.Path] = true
+		entry, err := zipReader.Open(file.Path)
+		if err != nil {
+			return manifest, fmt.Errorf(i18n.Tr("в архиве нет файла %s из описи"), file.Path)
+		}
+		hash := sha256.New()
+		size, err := io.Copy(hash, entry)
+		entry.Close()
+		if err != nil {
+			return manifest, fmt.Errorf(i18n.Tr("не удалось прочитать %s из архива: %w"), file.Path, err)
+		}
+		if size != file.Size || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+			return manifest, fmt.Errorf(i18n.Tr("файл %s в архиве не совпадает с описью"), file.Path)
+		}
+	}
+	if !listed[manifest.Marker] {
+		return manifest, fmt.Errorf(i18n.Tr("метка %q не указана в описи"), manifest.Marker)
+	}
+	for _, entry := range zipReader.File {
+		if entry.Name == ZipManifestName {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name, "/")
+		if (name != manifest.Order && !strings.HasPrefix(name, manifest.Order+"/")) || !fs.ValidPath(name) {
+			return manifest, fmt.Errorf(i18n.Tr("некорректный путь %q в архиве"), entry.Name)
+		}
+		if !strings.HasSuffix(entry.Name, "/") && !listed[entry.Name] {
+			return manifest, fmt.Errorf(i18n.Tr("файл %s в архиве отсутствует в описи"), entry.Name)
+		}
+	}
+	return manifest, nil
+}
+
+// Открывает zip-файл и проверяет его по описи
+func verifyZipFile(zipPath string) error {
+	zipReader, err := fileio.OpenZip(zipPath)
+	if err != nil {
+		return err
+	}
+	_, err = verifyZipArchive(zipReader)
+	return err
+}
+
+/**
+ * collectPanelTotals: Запоминает количество и площадь панелей в папке и всех вложенных папках,
+ * чтобы выгрузка отчёта могла показать их после упаковки заказа в архив.
+ * @param dirPath - Полный путь к папке.
+ * @param relPath - Путь к папке относительно стартовой.
+ * @param totals - Накопитель итогов по относительным путям.
+ */
+func collectPanelTotals(dirPath string, relPath string, totals map[string]panelTotals) {
+	panels, area := panel.FolderPanelTotals(dirPath)
+	totals[relPath] = panelTotals{panels: panels, area: area}
+	entries, err := fileio.ReadDir(dirPath)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			collectPanelTotals(filepath.Join(dirPath, entry.Name()), filepath.Join(relPath, entry.Name()), totals)
+		}
+	}
+}
+
+/**
+ * FindOrderZip: Находит zip-архив заказа по пути относительно TargetDir или по имени заказа.
+ * @return string - Полный путь к zip-файлу.
+ * @return error - Ошибка, если архив не найден или имя заказа неоднозначно.
+ */
+func FindOrderZip(name string, settings config.Settings) (string, error) {
+	fullPath := fileio.GetAbsoluteFilepath(settings.DirTarget, name)
+	if info, err := fileio.Stat(fullPath); err == nil && !info.IsDir() {
+		return fullPath, nil
+	}
+	var found []string
+	for _, orderRel := range FindArchivedOrders(settings.DirTarget, ".") {
+		if fileio.GetExtension(orderRel) == "zip" && strings.EqualFold(strings.TrimSuffix(filepath.Base(orderRel), ".zip"), name) {
+			found = append(found, filepath.Join(settings.DirTarget, orderRel))
+		}
+	}
+	sort.Strings(found)
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf(i18n.Tr("в %s не найден архив заказа %s"), settings.DirTarget, name)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf(i18n.Tr("заказ %s найден в нескольких архивах, укажите путь:\n  %s"), name, strings.Join(found, "\n  "))
+	}
+}
+
+/**
+ * RestoreOrderZip: Распаковывает проверенный zip-архив заказа в стартовую папку по сохранённому пути.
+ * @param zipPath - Полный путь к zip-файлу.
+ * @param startDir - Стартовая папка (SourceDir).
+ * @param keepReady - true, если метки готовности нужно сохранить.
+ * @return string - Полный путь к восстановленной папке заказа.
+ * @return error - Ошибка проверки или распаковки.
+ */
+func RestoreOrderZip(zipPath string, startDir string, keepReady bool) (string, error) {
+	zipReader, err := fileio.OpenZip(zipPath)
+	if err != nil {
+		return "", fmt.Errorf(i18n.Tr("не удалось открыть архив %s: %w"), zipPath, err)
+	}
+	manifest, err := verifyZipArchive(zipReader)
+	if err != nil {
+		return "", fmt.Errorf(i18n.Tr("архив %s повреждён: %w"), zipPath, err)
+	}
+	sourcePath := manifest.SourcePath
+	if sourcePath == "" || !fs.ValidPath(sourcePath) || path.Base(sourcePath) != manifest.Order {
+		sourcePath = manifest.Order
+	}
+	targetPath := filepath.Join(startDir, filepath.FromSlash(sourcePath))
+	if _, err := fileio.Stat(targetPath); err == nil {
+		return "", fmt.Errorf(i18n.Tr("папка %s уже существует"), targetPath)
+	}
+
+	var dirTimes []*zip.File
+	for _, entry := range zipReader.File {
+		if entry.Name == ZipManifestName {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimSuffix(entry.Name, "/"), manifest.Order)
+		filePath := filepath.Join(targetPath, filepath.FromSlash(rel))
+		if strings.HasSuffix(entry.Name, "/") {
+			if err := fileio.MkdirAll(filePath, 0777); err != nil {
+				return "", err
+			}
+			dirTimes = append(dirTimes, entry)
+			continue
+		}
+		baseName := path.Base(entry.Name)
+		if !keepReady && (walker.IsOrderMarkerName(baseName) || (strings.HasPrefix(baseName, "ready_") && fileio.GetExtension(baseName) == "xml")) {
+			continue
+		}
+		if err := extractZipEntry(entry, filePath); err != nil {
+			return "", fmt.Errorf(i18n.Tr("не удалось распаковать %s: %w"), entry.Name, err)
+		}
+	}
+	// время изменения папок - после распаковки файлов, иначе оно будет перезаписано
+	for i := len(dirTimes) - 1; i >= 0; i-- {
+		rel := strings.TrimPrefix(strings.TrimSuffix(dirTimes[i].Name, "/"), manifest.Order)
+		fileio.Chtimes(filepath.Join(targetPath, filepath.FromSlash(rel)), dirTimes[i].Modified)
+	}
+	return targetPath, nil
+}
+
+// Распаковывает файл из архива с сохранением времени изменения
+func extractZipEntry(entry *zip.File, filePath string) error {
+	if err := fileio.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		return err
+	}
+	in, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(in)
+	in.Close()
+	if err != nil {
+		return err
+	}
+	if err := fileio.WriteFile(filePath, data, 0644); err != nil {
+		return err
+	}
+	return fileio.Chtimes(filePath, entry.Modified)
+}
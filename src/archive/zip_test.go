@@ -0,0 +1,260 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+	"github.com/ProOwler/ListMaker/fixture/fixturetest"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Строит в памяти готовый заказ /src/Иванов/Заказ (метка order_ready и деталь в проекте Кухня) и папку /done
+func newTestOrder(t *testing.T) (*fileio.MemFileSystem, fixture.Tree, string) {
+	t.Helper()
+	memFS := fixturetest.Mem(t)
+	src := fixture.New("/src")
+	src.Panel("Иванов/Заказ/Кухня/1_1_Бок.xml", 700, 400, 1)
+	src.OrderMarker("Иванов/Заказ", walker.ReportObj{ItemName: "Заказ", DateReady: "2025-06-01", Status: walker.StatusReady})
+	fixture.New("/done")
+	return memFS, src, src.Path("Иванов/Заказ")
+}
+
+// partialRemoveFS: Файловая система, в которой RemoveAll удаляет только папку Кухня и возвращает ошибку,
+// как при открытом окне Проводника
+type partialRemoveFS struct {
+	*fileio.MemFileSystem
+}
+
+func (memFS partialRemoveFS) RemoveAll(path string) error {
+	memFS.MemFileSystem.RemoveAll(filepath.Join(path, "Кухня"))
+	return &fs.PathError{Op: "remove", Path: path, Err: errors.New("access denied")}
+}
+
+// Описание файла в описи по его содержимому
+func manifestFile(path string, content string) XZipFile {
+	hash := sha256.Sum256([]byte(content))
+	return XZipFile{Path: path, Size: int64(len(content)), SHA256: hex.EncodeToString(hash[:])}
+}
+
+// Собирает zip-архив в памяти из файлов и описи
+func buildZip(t *testing.T, manifest XZipManifest, files map[string]string) *zip.Reader {
+	t.Helper()
+	var out bytes.Buffer
+	zipWriter := zip.NewWriter(&out)
+	for name, content := range files {
+		entry, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Write([]byte(content))
+	}
+	if err := writeZipManifest(zipWriter, manifest); err != nil {
+		t.Fatal(err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zipReader
+}
+
+// Заказ упаковывается в проверенный архив, исходная папка удаляется
+func TestZipOrder(t *testing.T) {
+	// Arrange
+	_, _, orderPath := newTestOrder(t)
+	// Action
+	err := zipOrder(orderPath, "/done/Заказ.zip", "Иванов/Заказ", "2025-06-01")
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileio.IsValidDir(orderPath) {
+		t.Errorf("папка заказа %s не удалена", orderPath)
+	}
+	zipReader, err := fileio.OpenZip("/done/Заказ.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := verifyZipArchive(zipReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.SourcePath != "Иванов/Заказ" || manifest.DateReady != "2025-06-01" || len(manifest.File) != 2 {
+		t.Errorf("опись: %+v", manifest)
+	}
+	if _, err := fileio.Stat("/done/Заказ.zip.tmp"); err == nil {
+		t.Error("временный файл архива не удалён")
+	}
+}
+
+// Заказ без метки не упаковывается и остаётся на месте
+func TestZipOrderWithoutMarker(t *testing.T) {
+	// Arrange
+	_, src, _ := newTestOrder(t)
+	src.Panel("Иванов/Новый/Кухня/1_1_Бок.xml", 700, 400, 1)
+	// Action
+	err := zipOrder(src.Path("Иванов/Новый"), "/done/Новый.zip", "Иванов/Новый", "2025-06-01")
+	// Assert
+	if err == nil {
+		t.Fatal("ожидалась ошибка")
+	}
+	if !fileio.IsValidDir(src.Path("Иванов/Новый/Кухня")) {
+		t.Error("папка заказа удалена")
+	}
+	if _, err := fileio.Stat("/done/Новый.zip"); err == nil {
+		t.Error("архив создан")
+	}
+}
+
+// Папка удалена не полностью => ошибка, остаток переименован в <заказ>.zipped и в нём нет метки
+func TestZipOrderPartialRemove(t *testing.T) {
+	// Arrange
+	memFS, src, orderPath := newTestOrder(t)
+	fileio.SetFileSystem(partialRemoveFS{memFS})
+	// Action
+	err := zipOrder(orderPath, "/done/Заказ.zip", "Иванов/Заказ", "2025-06-01")
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "Заказ.zipped") {
+		t.Fatalf("ошибка: %v; want остаток Заказ.zipped", err)
+	}
+	if err := verifyZipFile("/done/Заказ.zip"); err != nil {
+		t.Errorf("архив: %v", err)
+	}
+	if fileio.IsValidDir(orderPath) || !fileio.IsValidDir(src.Path("Иванов/Заказ.zipped")) {
+		t.Error("остаток папки заказа не переименован")
+	}
+	if marker := walker.FindOrderMarker(src.Path("Иванов/Заказ.zipped")); marker != "" {
+		t.Errorf("в остатке осталась метка %s", marker)
+	}
+}
+
+func TestVerifyZipArchive(t *testing.T) {
+	const marker = "Заказ/order_ready_20250601.xml"
+	tests := []struct {
+		name     string
+		files    map[string]string // содержимое архива
+		listed   []XZipFile        // файлы в описи
+		noMarker bool              // метка не указана в описи
+		wantErr  string
+	}{
+		{
+			name:   "опись совпадает",
+			files:  map[string]string{marker: "<Root/>", "Заказ/Кухня/1.xml": "деталь"},
+			listed: []XZipFile{manifestFile(marker, "<Root/>"), manifestFile("Заказ/Кухня/1.xml", "деталь")},
+		},
+		{
+			name:    "путь в описи выходит за папку заказа",
+			files:   map[string]string{marker: "<Root/>", "Заказ/../evil.txt": "x"},
+			listed:  []XZipFile{manifestFile(marker, "<Root/>"), manifestFile("Заказ/../evil.txt", "x")},
+			wantErr: "некорректный путь",
+		},
+		{
+			name:    "файл вне описи выходит за папку заказа",
+			files:   map[string]string{marker: "<Root/>", "../evil.txt": "x"},
+			listed:  []XZipFile{manifestFile(marker, "<Root/>")},
+			wantErr: "некорректный путь",
+		},
+		{
+			name:    "содержимое не совпадает с описью",
+			files:   map[string]string{marker: "<Root/>", "Заказ/Кухня/1.xml": "другая деталь"},
+			listed:  []XZipFile{manifestFile(marker, "<Root/>"), manifestFile("Заказ/Кухня/1.xml", "деталь")},
+			wantErr: "не совпадает с описью",
+		},
+		{
+			name:    "файл отсутствует в описи",
+			files:   map[string]string{marker: "<Root/>", "Заказ/Кухня/1.xml": "деталь"},
+			listed:  []XZipFile{manifestFile(marker, "<Root/>")},
+			wantErr: "отсутствует в описи",
+		},
+		{
+			name:    "файла из описи нет в архиве",
+			files:   map[string]string{marker: "<Root/>"},
+			listed:  []XZipFile{manifestFile(marker, "<Root/>"), manifestFile("Заказ/Кухня/1.xml", "деталь")},
+			wantErr: "нет файла",
+		},
+		{
+			name:     "метка не указана в описи",
+			files:    map[string]string{marker: "<Root/>"},
+			listed:   []XZipFile{manifestFile(marker, "<Root/>")},
+			noMarker: true,
+			wantErr:  "не указана в описи",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			manifest := XZipManifest{Version: c_MANIFEST_VERSION, Order: "Заказ", Marker: marker, File: tt.listed}
+			if tt.noMarker {
+				manifest.Marker = "Заказ/order_ready_20250602.xml"
+			}
+			zipReader := buildZip(t, manifest, tt.files)
+			// Action
+			_, err := verifyZipArchive(zipReader)
+			// Assert
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ошибка: %v; want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ошибка: %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Заказ восстанавливается по сохранённому пути без меток готовности, существующая папка не перезаписывается
+func TestRestoreOrderZip(t *testing.T) {
+	// Arrange
+	_, src, orderPath := newTestOrder(t)
+	if err := zipOrder(orderPath, "/done/Заказ.zip", "Иванов/Заказ", "2025-06-01"); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	restored, err := RestoreOrderZip("/done/Заказ.zip", "/src", false)
+	_, errAgain := RestoreOrderZip("/done/Заказ.zip", "/src", false)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != orderPath {
+		t.Errorf("восстановлено в %s; want %s", restored, orderPath)
+	}
+	if data, err := fileio.ReadFile(src.Path("Иванов/Заказ/Кухня/1_1_Бок.xml")); err != nil || !bytes.Equal(data, fixture.PanelXML(700, 400, 1)) {
+		t.Errorf("деталь не восстановлена: %v", err)
+	}
+	if marker := walker.FindOrderMarker(orderPath); marker != "" {
+		t.Errorf("метка %s восстановлена без -keep-ready", marker)
+	}
+	if errAgain == nil {
+		t.Error("существующая папка перезаписана")
+	}
+}
+
+// С keepReady метка order_ready восстанавливается
+func TestRestoreOrderZipKeepReady(t *testing.T) {
+	// Arrange
+	_, _, orderPath := newTestOrder(t)
+	if err := zipOrder(orderPath, "/done/Заказ.zip", "Иванов/Заказ", "2025-06-01"); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	_, err := RestoreOrderZip("/done/Заказ.zip", "/src", true)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if walker.FindOrderMarker(orderPath) == "" {
+		t.Error("метка не восстановлена")
+	}
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/report"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// команды программы, передаваемые первым аргументом командной строки
+const (
+	c_CMD_DIFF    = "diff"          // сравнение сохранённых отчётов о работе
+	c_CMD_RESTORE = "restore"       // распаковка заказа из zip-архива в SourceDir
+	c_CMD_CLEANUP = "cleanup"       // применение правил хранения к архиву
+	c_CMD_NAMES   = "check-names"   // проверка имён файлов по правилам разбора из настроек
+	c_CMD_REFRESH = "refresh-lists" // пересоздание списков работ, не совпадающих с файлами папок
+	c_CMD_STATUS  = "status"        // дерево статусов заказов без изменения файлов
+)
+
+/**
+ * runCommand: Выполняет команду, если первый аргумент командной строки - имя команды.
+ * Иначе аргумент считается стартовой папкой и обрабатывается основным конвейером.
+ * @param name - Первый аргумент командной строки.
+ * @param args - Остальные аргументы.
+ * @param settings - Загруженные настройки программы.
+ * @return bool - true, если команда распознана и выполнена.
+ */
+func runCommand(name string, args []string, settings config.Settings) bool {
+	switch name {
+	case c_CMD_DIFF:
+		runDiff(args, settings)
+	case c_CMD_RESTORE:
+		runRestore(args, settings)
+	case c_CMD_CLEANUP:
+		runCleanup(args, settings)
+	case c_CMD_NAMES:
+		runCheckNames(args, settings)
+	case c_CMD_REFRESH:
+		runRefreshLists(args, settings)
+	case c_CMD_STATUS:
+		runStatus(args, settings)
+	default:
+		return false
+	}
+	return true
+}
+
+/**
+ * isReadOnlyCommand: Проверяет, что команда только читает файлы и может работать одновременно с обработкой.
+ */
+func isReadOnlyCommand(name string) bool {
+	return name == c_CMD_DIFF || name == c_CMD_NAMES || name == c_CMD_STATUS
+}
+
+/**
+ * runDiff: Команда diff - сравнивает два сохранённых отчёта о работе и выводит изменения.
+ * Без аргументов сравниваются два последних отчёта, с одним - указанный и последний,
+ * с двумя - указанные. Относительные пути отсчитываются от TargetDir.
+ * С ключом -current новой стороной служит текущее состояние SourceDir (обход без изменений, как в status),
+ * старой - указанный отчёт или последний сохранённый.
+ * @param args - Аргументы команды.
+ * @param settings - Настройки программы.
+ */
+func runDiff(args []string, settings config.Settings) {
+	current := false
+	var files []string
+	for _, arg := range args {
+		if arg == "-current" {
+			current = true
+		} else {
+			files = append(files, arg)
+		}
+	}
+	if len(files) > 2 || current && len(files) > 1 {
+		fmt.Println(i18n.Tr("Использование: diff [старый отчёт [новый отчёт]] | diff -current [отчёт]"))
+		return
+	}
+	var oldFile, newFile string
+	saved := walker.FindSavedReports(settings)
+	switch {
+	case len(files) == 0 && current:
+		if len(saved) == 0 {
+			fmt.Printf(i18n.Tr("В %s нет сохранённых отчётов\n"), settings.DirTarget)
+			return
+		}
+		oldFile = saved[len(saved)-1]
+	case len(files) == 0:
+		if len(saved) < 2 {
+			fmt.Printf(i18n.Tr("Для сравнения нужно хотя бы два сохранённых отчёта в %s\n"), settings.DirTarget)
+			return
+		}
+		oldFile, newFile = saved[len(saved)-2], saved[len(saved)-1]
+	case len(files) == 1 && current:
+		oldFile = fileio.GetAbsoluteFilepath(settings.DirTarget, files[0])
+	case len(files) == 1:
+		if len(saved) == 0 {
+			fmt.Printf(i18n.Tr("В %s нет сохранённых отчётов\n"), settings.DirTarget)
+			return
+		}
+		oldFile, newFile = fileio.GetAbsoluteFilepath(settings.DirTarget, files[0]), saved[len(saved)-1]
+	default:
+		oldFile, newFile = fileio.GetAbsoluteFilepath(settings.DirTarget, files[0]), fileio.GetAbsoluteFilepath(settings.DirTarget, files[1])
+	}
+	oldReports, errOld := walker.ReadSavedReport(oldFile)
+	if errOld != nil {
+		fmt.Printf(i18n.Tr("Ошибка: %v\n"), errOld)
+		return
+	}
+	var newReports []walker.ReportObj
+	if current {
+		newFile = settings.DirSource
+		newReports = walker.WalkReadOnly(settings.DirSource, settings).InnerItems
+		for i := range newReports {
+			newReports[i].AssignLevels(1, &settings)
+		}
+	} else {
+		var errNew error
+		newReports, errNew = walker.ReadSavedReport(newFile)
+		if errNew != nil {
+			fmt.Printf(i18n.Tr("Ошибка: %v\n"), errNew)
+			return
+		}
+	}
+	fmt.Printf(i18n.Tr("Сравнение отчётов:\n  %s\n  %s\n\n"), oldFile, newFile)
+	fmt.Print(report.Compare(oldReports, newReports, settings).String())
+}
+
+/**
+ * runRestore: Команда restore - распаковывает заказ из zip-архива обратно в SourceDir (повторный заказ).
+ * Архив указывается путём относительно TargetDir или именем заказа. Архив проверяется по описи,
+ * заказ восстанавливается на прежнее место; существующая папка не перезаписывается.
+ * Метки готовности (order_ready_*, ready_*) удаляются, чтобы заказ снова попал в работу,
+ * если не указан ключ -keep-ready. Сам архив сохраняется.
+ * @param args - Аргументы команды.
+ * @param settings - Настройки программы.
+ */
+func runRestore(args []string, settings config.Settings) {
+	keepReady := false
+	var names []string
+	for _, arg := range args {
+		if arg == "-keep-ready" {
+			keepReady = true
+		} else {
+			names = append(names, arg)
+		}
+	}
+	if len(names) != 1 {
+		fmt.Println(i18n.Tr("Использование: restore [-keep-ready] <zip-файл относительно TargetDir или имя заказа>"))
+		return
+	}
+	zipPath, err := archive.FindOrderZip(names[0], settings)
+	if err != nil {
+		fmt.Printf(i18n.Tr("Ошибка: %v\n"), err)
+		return
+	}
+	targetPath, err := archive.RestoreOrderZip(zipPath, settings.DirSource, keepReady)
+	if err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка: %v"), err), logging.FieldPath, zipPath, logging.FieldAction, "restore", logging.FieldError, err)
+		return
+	}
+	logging.Info(fmt.Sprintf(i18n.Tr("Заказ из архива %s восстановлен в %s"), zipPath, targetPath), logging.FieldPath, targetPath, logging.FieldAction, "restore", "zip", zipPath)
+}
+
+/**
+ * runCleanup: Команда cleanup - применяет правила хранения к TargetDir (см. Cleanup).
+ * С ключом -dry-run только выводит, что было бы сделано.
+ * @param args - Аргументы команды.
+ * @param settings - Настройки программы.
+ */
+func runCleanup(args []string, settings config.Settings) {
+	dryRun := false
+	for _, arg := range args {
+		if arg == "-dry-run" {
+			dryRun = true
+		} else {
+			fmt.Println(i18n.Tr("Использование: cleanup [-dry-run]"))
+			return
+		}
+	}
+	if settings.Retention.IsEmpty() {
+		fmt.Println(i18n.Tr("Правила хранения (Retention) в файле настроек не заданы"))
+		return
+	}
+	if dryRun {
+		fmt.Println(i18n.Tr("Пробный запуск: изменения не вносятся"))
+	}
+	if err := archive.Cleanup(settings, dryRun); err != nil {
+		fmt.Printf(i18n.Tr("Ошибка: %v\n"), err)
+	}
+}
+
+/**
+ * runCheckNames: Команда check-names - проверяет имена файлов-заданий и выполненных плейлистов
+ * по правилам разбора из настроек (FileNames) и выводит файлы, имена которых им не соответствуют.
+ * Файлы-задания в каждой папке определяются профилем её станка (MachineFor), как при обходе.
+ * Проверяется SourceDir или указанная папка (относительно SourceDir), игнорируемые папки пропускаются.
+ * @param args - Аргументы команды.
+ * @param settings - Настройки программы.
+ */
+func runCheckNames(args []string, settings config.Settings) {
+	if len(args) > 1 {
+		fmt.Println(i18n.Tr("Использование: check-names [папка относительно SourceDir]"))
+		return
+	}
+	startDir := settings.DirSource
+	if len(args) == 1 {
+		startDir = fileio.GetAbsoluteFilepath(settings.DirSource, args[0])
+	}
+	fmt.Printf(i18n.Tr("Правила имён файлов: %s\n"), settings.NameGrammar)
+	var checked, mismatched int
+	// профили станков просмотренных папок
+	machines := make(map[string]config.MachineProfile)
+	err := fileio.WalkDir(startDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Printf(i18n.Tr("Ошибка: %v\n"), err)
+			return nil
+		}
+		if entry.IsDir() {
+			if path != startDir && settings.IsIgnored(path) {
+				return filepath.SkipDir
+			}
+			machines[path] = machineForDir(path, settings)
+			return nil
+		}
+		if fileio.IsTempFile(entry.Name()) {
+			return nil
+		}
+		machine := machines[filepath.Dir(path)]
+		isChecked, errName := settings.NameGrammar.CheckFileName(entry.Name(), machine.IsJobFile(entry.Name()))
+		if !isChecked {
+			return nil
+		}
+		checked++
+		if errName != nil {
+			mismatched++
+			relPath, _ := filepath.Rel(startDir, path)
+			fmt.Printf("  %s: %v\n", relPath, errName)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf(i18n.Tr("Ошибка: %v\n"), err)
+		return
+	}
+	fmt.Printf(i18n.Tr("Проверено файлов: %d, не соответствуют правилам: %d\n"), checked, mismatched)
+}
+
+// Профиль станка папки по её имени и файлам, как при обходе
+func machineForDir(dirPath string, settings config.Settings) config.MachineProfile {
+	var shortFileNames []string
+	if dirEntries, err := fileio.ReadDir(dirPath); err == nil {
+		for _, entry := range dirEntries {
+			if !entry.IsDir() && !fileio.IsTempFile(entry.Name()) {
+				shortFileNames = append(shortFileNames, entry.Name())
+			}
+		}
+	}
+	return settings.MachineFor(filepath.Base(dirPath), shortFileNames)
+}
+
+/**
+ * runRefreshLists: Команда refresh-lists - пересоздаёт списки работ, которые не совпадают с файлами-заданиями
+ * своих папок (файлы добавлены, удалены или изменены после записи списка). Сделанное станком количество
+ * сохраняется для неизменённых деталей. Проверяется SourceDir или указанная папка (относительно SourceDir),
+ * игнорируемые папки пропускаются.
+ * @param args - Аргументы команды.
+ * @param settings - Настройки программы.
+ */
+func runRefreshLists(args []string, settings config.Settings) {
+	if len(args) > 1 {
+		fmt.Println(i18n.Tr("Использование: refresh-lists [папка относительно SourceDir]"))
+		return
+	}
+	startDir := settings.DirSource
+	if len(args) == 1 {
+		startDir = fileio.GetAbsoluteFilepath(settings.DirSource, args[0])
+	}
+	var checked, refreshed int
+	err := fileio.WalkDir(startDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Printf(i18n.Tr("Ошибка: %v\n"), err)
+			return nil
+		}
+		if entry.IsDir() {
+			if path != startDir && settings.IsIgnored(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !settings.IsListFile(entry.Name()) {
+			return nil
+		}
+		checked++
+		relPath, _ := filepath.Rel(startDir, path)
+		check, errCheck := walker.CheckListFile(path, settings)
+		if errCheck != nil {
+			fmt.Printf("  %s: %v\n", relPath, errCheck)
+			return nil
+		}
+		if !check.IsStale() {
+			return nil
+		}
+		if errRefresh := walker.RefreshList(check, settings); errRefresh != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Ошибка обновления списка заданий %s: %v"), path, errRefresh),
+				logging.FieldPath, path, logging.FieldAction, "refresh-list", logging.FieldError, errRefresh)
+			return nil
+		}
+		refreshed++
+		logging.Info(fmt.Sprintf(i18n.Tr("Список заданий %s обновлён (%s)"), path, check),
+			logging.FieldPath, path, logging.FieldAction, "refresh-list", "machine", check.Machine.Name)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf(i18n.Tr("Ошибка: %v\n"), err)
+		return
+	}
+	fmt.Printf(i18n.Tr("Проверено списков: %d, обновлено: %d\n"), checked, refreshed)
+}
+
+/**
+ * runStatus: Команда status - выводит дерево заказов (заказчик → заказ → проект) со статусами, датами
+ * готовности и прогрессом, ничего не меняя в папках (см. walker.WalkReadOnly).
+ * Проверяется SourceDir или указанная папка (относительно SourceDir). Ключ -all выводит и папки раскроев,
+ * -color и -no-color включают и выключают цвета (по умолчанию - если вывод в консоль, см. useColors).
+ * @param args - Аргументы команды.
+ * @param settings - Настройки программы.
+ */
+func runStatus(args []string, settings config.Settings) {
+	var all, forceColor, noColor bool
+	var dirs []string
+	for _, arg := range args {
+		switch arg {
+		case "-all":
+			all = true
+		case "-color":
+			forceColor = true
+		case "-no-color":
+			noColor = true
+		default:
+			dirs = append(dirs, arg)
+		}
+	}
+	if len(dirs) > 1 || forceColor && noColor {
+		fmt.Println(i18n.Tr("Использование: status [-all] [-color | -no-color] [папка относительно SourceDir]"))
+		return
+	}
+	startDir := settings.DirSource
+	if len(dirs) == 1 {
+		startDir = fileio.GetAbsoluteFilepath(settings.DirSource, dirs[0])
+	}
+	if info, err := fileio.Stat(startDir); err != nil || !info.IsDir() {
+		fmt.Printf(i18n.Tr("Папка %s не найдена\n"), startDir)
+		return
+	}
+	reports := walker.WalkReadOnly(startDir, settings).InnerItems
+	for i := range reports {
+		reports[i].AssignLevels(1, &settings)
+	}
+	if len(reports) == 0 {
+		fmt.Printf(i18n.Tr("В папке %s нет заказов\n"), startDir)
+		return
+	}
+	fmt.Printf("%s\n", startDir)
+	fmt.Print(report.CreateStatusTree(reports, startDir, settings, useColors(forceColor, noColor), all))
+}
+
+/**
+ * useColors: Определяет, выделять ли вывод цветом (ANSI).
+ * Ключи важнее всего; иначе цвета выключает переменная окружения NO_COLOR и вывод в файл или канал.
+ * В Windows цвета по умолчанию включаются только в терминалах с поддержкой ANSI (Windows Terminal, ConEmu).
+ * @param forced - Указан ключ -color.
+ * @param disabled - Указан ключ -no-color.
+ */
+func useColors(forced bool, disabled bool) bool {
+	if forced || disabled {
+		return forced
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return os.Getenv("WT_SESSION") != "" || os.Getenv("ConEmuANSI") == "ON"
+	}
+	return os.Getenv("TERM") != "dumb"
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// XArchive: Настройки перемещения готовых заказов в архив в XML
+type XArchive struct {
+	PathTemplate string `xml:"PathTemplate,attr"` // Шаблон пути заказа относительно TargetDir
+	GraceDays    int    `xml:"GraceDays,attr"`    // Через сколько дней после готовности заказ перемещается
+	Compress     bool   `xml:"Compress,attr"`     // Упаковывать заказ в zip-архив вместо перемещения папки
+}
+
+// Шаблон пути по умолчанию: TargetDir/yyyy-mm/<вышестоящие папки>/<заказ>
+const defaultArchivePath = "{year}-{month}/{path}"
+
+// подстановки в шаблоне пути архива
+var archivePlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+var archivePlaceholders = map[string]bool{
+	"year":     true, // год готовности, yyyy
+	"month":    true, // месяц готовности, mm
+	"day":      true, // день готовности, dd
+	"week":     true, // номер недели готовности по ISO 8601, ww
+	"weekyear": true, // год, к которому относится неделя по ISO 8601
+	"customer": true, // имя папки заказчика (уровень customer)
+	"project":  true, // имя папки проекта (уровень project)
+	"order":    true, // имя папки заказа
+	"path":     true, // путь к заказу от стартовой папки
+}
+
+/**
+ * validateArchivePath: Проверяет шаблон пути архива: известные подстановки, относительный путь
+ * без "..", наличие {order} или {path}, чтобы заказы не сливались в одну папку.
+ */
+func validateArchivePath(template string) error {
+	for _, match := range archivePlaceholder.FindAllStringSubmatch(template, -1) {
+		if !archivePlaceholders[match[1]] {
+			return fmt.Errorf(i18n.Tr("неизвестная подстановка %s"), match[0])
+		}
+	}
+	if !strings.Contains(template, "{order}") && !strings.Contains(template, "{path}") {
+		return fmt.Errorf(i18n.Tr("шаблон %q должен содержать {order} или {path}"), template)
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(template), "/") {
+		if segment == ".." {
+			return fmt.Errorf(i18n.Tr("шаблон %q не должен выходить за пределы TargetDir"), template)
+		}
+	}
+	if filepath.IsAbs(template) || strings.HasPrefix(template, "/") {
+		return fmt.Errorf(i18n.Tr("шаблон %q должен быть относительным"), template)
+	}
+	return nil
+}
+
+/**
+ * ExpandArchivePath: Подставляет значения в шаблон пути архива.
+ * Пустые части пути пропускаются.
+ * @param template - Шаблон пути (проверенный validateArchivePath).
+ * @param values - Значения подстановок без фигурных скобок (year, order, ...).
+ * @return string - Относительный путь.
+ */
+func ExpandArchivePath(template string, values map[string]string) string {
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(template), "/") {
+		segments = append(segments, archivePlaceholder.ReplaceAllStringFunc(segment, func(m string) string {
+			return values[m[1:len(m)-1]]
+		}))
+	}
+	return filepath.Join(segments...)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/ProOwler/ListMaker/panel"
+)
+
+// XFileNames: Правила разбора имён файлов в XML (регулярные выражения с именованными группами)
+type XFileNames struct {
+	Pattern      string `xml:"Pattern"`         // Имя файла-задания без расширения: группы id, qty, по желанию material, date, suffix
+	ReadyPattern string `xml:"ReadyPattern"`    // Имя выполненного плейлиста без расширения: группа date
+	DateLayout   string `xml:"DateLayout,attr"` // Формат даты в группе date (как в time.Parse), например 20060102
+}
+
+/**
+ * parseFileNames: Преобразует правила разбора имён из файла настроек во внутреннее представление.
+ * Незаданные шаблоны и формат даты берутся по умолчанию (ID_КОЛИЧЕСТВО_..., ready_yyyymmdd).
+ * @param xFileNames - Описание из XML, nil - правила по умолчанию.
+ * @return *panel.NameGrammar - Правила разбора.
+ * @return error - Ошибка, если шаблон или формат даты некорректен.
+ */
+func parseFileNames(xFileNames *XFileNames) (*panel.NameGrammar, error) {
+	if xFileNames == nil {
+		return panel.DefaultNameGrammar(), nil
+	}
+	namePattern := strings.TrimSpace(xFileNames.Pattern)
+	if namePattern == "" {
+		namePattern = panel.DefaultNamePattern
+	}
+	readyPattern := strings.TrimSpace(xFileNames.ReadyPattern)
+	if readyPattern == "" {
+		readyPattern = panel.DefaultReadyPattern
+	}
+	dateLayout := strings.TrimSpace(xFileNames.DateLayout)
+	if dateLayout == "" {
+		dateLayout = panel.DefaultDateLayout
+	}
+	return panel.NewNameGrammar(namePattern, readyPattern, dateLayout)
+}
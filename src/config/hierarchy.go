@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// виды уровней иерархии папок
+const (
+	LevelCustomer = "customer" // заказчик
+	LevelOrder    = "order"    // заказ - единица перемещения в архив
+	LevelProject  = "project"  // проект
+	LevelMaterial = "material" // раскрой по материалу - папка с файлами-заданиями
+)
+
+// названия уровней в отчётах, если в настройках не указан Title
+var levelTitles = map[string]string{
+	LevelCustomer: "Заказчик",
+	LevelOrder:    "Заказ",
+	LevelProject:  "Проект",
+	LevelMaterial: "Материал",
+}
+
+// XHierarchy: Описание уровней иерархии папок в XML
+type XHierarchy struct {
+	Level []XHierarchyLevel `xml:"Level"`
+}
+
+// XHierarchyLevel: Уровень иерархии в XML
+type XHierarchyLevel struct {
+	Kind    string `xml:"Kind,attr"`    // customer, order, project, material
+	Title   string `xml:"Title,attr"`   // Название для отчётов
+	Depth   int    `xml:"Depth,attr"`   // Глубина от стартовой папки (1 - папки верхнего уровня)
+	Pattern string `xml:"Pattern,attr"` // Шаблон имени папки, имеет приоритет над глубиной
+}
+
+// HierarchyLevel: Именованный уровень иерархии папок
+type HierarchyLevel struct {
+	Kind    string
+	Title   string
+	Depth   int    // 0 - глубина не задана
+	Pattern string // пустой - шаблон не задан
+}
+
+// Иерархия по умолчанию соответствует прежнему поведению: заказы в стартовой папке,
+// в них проекты, в конечных папках - раскрои по материалам
+var defaultHierarchy = []HierarchyLevel{
+	{Kind: LevelOrder, Title: levelTitles[LevelOrder], Depth: 1},
+	{Kind: LevelProject, Title: levelTitles[LevelProject], Depth: 2},
+	{Kind: LevelMaterial, Title: levelTitles[LevelMaterial]},
+}
+
+/**
+ * parseHierarchy: Преобразует описание иерархии из файла настроек во внутреннее представление.
+ * @param xHierarchy - Описание из XML, nil - используется иерархия по умолчанию.
+ * @return []HierarchyLevel - Уровни в порядке описания.
+ * @return error - Ошибка, если вид уровня неизвестен, повторяется или шаблон некорректен.
+ */
+func parseHierarchy(xHierarchy *XHierarchy) ([]HierarchyLevel, error) {
+	if xHierarchy == nil || len(xHierarchy.Level) == 0 {
+		return defaultHierarchy, nil
+	}
+	var result []HierarchyLevel
+	seen := make(map[string]bool)
+	for _, el := range xHierarchy.Level {
+		kind := strings.ToLower(strings.TrimSpace(el.Kind))
+		if _, known := levelTitles[kind]; !known {
+			return nil, fmt.Errorf(i18n.Tr("неизвестный вид уровня иерархии %q"), el.Kind)
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf(i18n.Tr("уровень иерархии %q указан дважды"), kind)
+		}
+		seen[kind] = true
+		if _, err := filepath.Match(el.Pattern, ""); err != nil {
+			return nil, fmt.Errorf(i18n.Tr("некорректный шаблон %q уровня %s: %w"), el.Pattern, kind, err)
+		}
+		title := el.Title
+		if title == "" {
+			title = levelTitles[kind]
+		}
+		result = append(result, HierarchyLevel{Kind: kind, Title: title, Depth: el.Depth, Pattern: el.Pattern})
+	}
+	return result, nil
+}
+
+/**
+ * LevelKind: Определяет вид уровня папки.
+ * Порядок: совпадение с шаблоном имени, затем для конечных папок - уровень материала без глубины,
+ * затем совпадение по глубине.
+ * @param dirName - Имя папки.
+ * @param depth - Глубина от стартовой папки (1 - папки верхнего уровня).
+ * @param isLeaf - true, если во вложенных папках нет отчётов (конечная папка).
+ * @return string - Вид уровня (Level*) или пустая строка, если уровень не определён.
+ */
+func (settings *Settings) LevelKind(dirName string, depth int, isLeaf bool) string {
+	for _, level := range settings.Hierarchy {
+		if level.Pattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(strings.ToLower(level.Pattern), strings.ToLower(dirName)); ok {
+			return level.Kind
+		}
+	}
+	if isLeaf {
+		for _, level := range settings.Hierarchy {
+			if level.Kind == LevelMaterial && level.Depth == 0 {
+				return level.Kind
+			}
+		}
+	}
+	for _, level := range settings.Hierarchy {
+		if level.Depth == depth {
+			return level.Kind
+		}
+	}
+	return ""
+}
+
+/**
+ * LevelTitle: Возвращает название уровня для отчётов.
+ */
+func (settings *Settings) LevelTitle(kind string) string {
+	for _, level := range settings.Hierarchy {
+		if level.Kind == kind && level.Title != levelTitles[kind] {
+			return level.Title
+		}
+	}
+	// стандартные названия переводятся, заданные в настройках выводятся как есть
+	return i18n.Tr(levelTitles[kind])
+}
+
+/**
+ * LevelDepth: Возвращает глубину уровня из описания иерархии или 1, если глубина не задана.
+ */
+func (settings *Settings) LevelDepth(kind string) int {
+	for _, level := range settings.Hierarchy {
+		if level.Kind == kind && level.Depth > 0 {
+			return level.Depth
+		}
+	}
+	return 1
+}
+
+/**
+ * HasLevel: Проверяет, описан ли уровень в иерархии.
+ */
+func (settings *Settings) HasLevel(kind string) bool {
+	for _, level := range settings.Hierarchy {
+		if level.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
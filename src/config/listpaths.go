@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// XListPaths: Правила записи путей к файлам-заданиям (FilePath) в list.xml
+type XListPaths struct {
+	Relative  bool        `xml:"Relative,attr"`  // Пути относительно папки list.xml
+	Separator string      `xml:"Separator,attr"` // windows, unix или пусто - как в системе
+	Map       []XPathRule `xml:"Map"`
+}
+
+// XPathRule: Замена начала пути в XML
+type XPathRule struct {
+	From string `xml:"From,attr"` // Начало пути на компьютере, где работает программа
+	To   string `xml:"To,attr"`   // Начало того же пути на станке
+}
+
+/**
+ * parseListPaths: Преобразует правила записи путей из файла настроек во внутреннее представление.
+ * Без элемента ListPaths пути записываются полностью, как их видит программа.
+ * @return error - Ошибка, если разделитель неизвестен или у замены не указано начало пути.
+ */
+func parseListPaths(xListPaths *XListPaths) (worklist.PathMapping, error) {
+	if xListPaths == nil {
+		return worklist.PathMapping{}, nil
+	}
+	mapping := worklist.PathMapping{
+		Relative:  xListPaths.Relative,
+		Separator: strings.ToLower(strings.TrimSpace(xListPaths.Separator)),
+	}
+	for _, el := range xListPaths.Map {
+		mapping.Rules = append(mapping.Rules, worklist.PathRule{From: strings.TrimSpace(el.From), To: strings.TrimSpace(el.To)})
+	}
+	return mapping, mapping.Validate()
+}
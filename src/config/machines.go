@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// XMachines: Станки (профили списков работ) в XML
+type XMachines struct {
+	Machine []XMachine `xml:"Machine"`
+}
+
+// XMachine: Профиль списка работ для станка в XML
+type XMachine struct {
+	Name       string      `xml:"Name,attr"`       // Название станка для журнала
+	ListFile   string      `xml:"ListFile,attr"`   // Имя файла списка, по умолчанию list.xml
+	Format     string      `xml:"Format,attr"`     // worklist (по умолчанию) или text
+	Pattern    string      `xml:"Pattern,attr"`    // Шаблон имени папки (как в filepath.Match, без учёта регистра)
+	MarkerFile string      `xml:"MarkerFile,attr"` // Имя файла-метки, наличие которого в папке выбирает станок
+	FileType   []XFileType `xml:"FileType"`        // Типы файлов-заданий, по умолчанию xml (11) и mpr (7)
+	ListPaths  *XListPaths `xml:"ListPaths"`       // Правила записи путей, по умолчанию общие ListPaths
+}
+
+// XFileType: Тип файлов-заданий в XML
+type XFileType struct {
+	Code string `xml:"Code,attr"` // Код типа в FileType списка работ
+	Ext  string `xml:"Ext,attr"`  // Расширение без точки
+}
+
+// MachineProfile: Профиль списка работ: для какого станка, какие файлы и в каком виде попадают в список
+type MachineProfile struct {
+	Name       string
+	ListFile   string
+	Format     string
+	FileTypes  worklist.FileFormats
+	ListPaths  worklist.PathMapping
+	Pattern    string // пустой - станок не выбирается по имени папки
+	MarkerFile string // пустой - станок не выбирается по файлу-метке
+}
+
+/**
+ * parseMachines: Преобразует профили станков из файла настроек во внутреннее представление.
+ * @param xMachines - Описание из XML, nil - профилей нет (все папки - профиль по умолчанию).
+ * @param listPaths - Общие правила записи путей, для профилей без своих ListPaths.
+ * @return []MachineProfile - Профили в порядке описания.
+ * @return error - Ошибка, если название пустое или повторяется, формат неизвестен,
+ * имя файла списка некорректно, шаблон папки некорректен или тип файлов описан не полностью.
+ */
+func parseMachines(xMachines *XMachines, listPaths worklist.PathMapping) ([]MachineProfile, error) {
+	if xMachines == nil {
+		return nil, nil
+	}
+	var result []MachineProfile
+	seen := make(map[string]bool)
+	for _, el := range xMachines.Machine {
+		profile := MachineProfile{
+			Name:       strings.TrimSpace(el.Name),
+			ListFile:   strings.TrimSpace(el.ListFile),
+			Format:     strings.ToLower(strings.TrimSpace(el.Format)),
+			ListPaths:  listPaths,
+			Pattern:    strings.TrimSpace(el.Pattern),
+			MarkerFile: strings.TrimSpace(el.MarkerFile),
+		}
+		if profile.Name == "" || seen[strings.ToLower(profile.Name)] {
+			return nil, fmt.Errorf(i18n.Tr("у станка пустое или повторяющееся название %q"), el.Name)
+		}
+		seen[strings.ToLower(profile.Name)] = true
+		if profile.ListFile == "" {
+			profile.ListFile = worklist.ListFileName
+		}
+		// файл списка не должен приниматься за файл-задание или выполненный плейлист
+		if strings.ContainsAny(profile.ListFile, `/\`) || strings.Contains(strings.ToLower(profile.ListFile), "ready") {
+			return nil, fmt.Errorf(i18n.Tr("некорректное имя файла списка %q у станка %s"), profile.ListFile, profile.Name)
+		}
+		if profile.Format == "" {
+			profile.Format = worklist.FormatWorkList
+		}
+		if !worklist.IsKnownFormat(profile.Format) {
+			return nil, fmt.Errorf(i18n.Tr("неизвестный формат списка %q у станка %s"), el.Format, profile.Name)
+		}
+		if _, err := filepath.Match(profile.Pattern, ""); err != nil {
+			return nil, fmt.Errorf(i18n.Tr("некорректный шаблон %q у станка %s: %w"), profile.Pattern, profile.Name, err)
+		}
+		profile.FileTypes = worklist.DefaultFileFormats
+		if len(el.FileType) > 0 {
+			profile.FileTypes = make(worklist.FileFormats)
+			for _, fileType := range el.FileType {
+				code, ext := strings.TrimSpace(fileType.Code), strings.ToLower(strings.TrimPrefix(strings.TrimSpace(fileType.Ext), "."))
+				if code == "" || ext == "" {
+					return nil, fmt.Errorf(i18n.Tr("у станка %s тип файлов без кода или расширения"), profile.Name)
+				}
+				profile.FileTypes[code] = ext
+			}
+		}
+		if el.ListPaths != nil {
+			mapping, err := parseListPaths(el.ListPaths)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", profile.Name, err)
+			}
+			profile.ListPaths = mapping
+		}
+		result = append(result, profile)
+	}
+	return result, nil
+}
+
+/**
+ * DefaultMachine: Профиль по умолчанию: list.xml в формате WorkList, файлы xml и mpr, общие ListPaths.
+ */
+func (settings *Settings) DefaultMachine() MachineProfile {
+	return MachineProfile{
+		ListFile:  worklist.ListFileName,
+		Format:    worklist.FormatWorkList,
+		FileTypes: worklist.DefaultFileFormats,
+		ListPaths: settings.ListPaths,
+	}
+}
+
+/**
+ * MachineFor: Выбирает профиль станка для папки с файлами-заданиями.
+ * Порядок: файл-метка станка в папке, затем шаблон имени папки, затем первый профиль без метки
+ * и шаблона, затем профиль по умолчанию.
+ * @param dirName - Имя папки (без пути).
+ * @param shortFileNames - Имена файлов в папке.
+ * @return MachineProfile - Профиль списка работ.
+ */
+func (settings *Settings) MachineFor(dirName string, shortFileNames []string) MachineProfile {
+	for _, profile := range settings.Machines {
+		if profile.MarkerFile == "" {
+			continue
+		}
+		for _, name := range shortFileNames {
+			if strings.EqualFold(name, profile.MarkerFile) {
+				return profile
+			}
+		}
+	}
+	for _, profile := range settings.Machines {
+		if profile.Pattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(strings.ToLower(profile.Pattern), strings.ToLower(dirName)); ok {
+			return profile
+		}
+	}
+	for _, profile := range settings.Machines {
+		if profile.Pattern == "" && profile.MarkerFile == "" {
+			return profile
+		}
+	}
+	return settings.DefaultMachine()
+}
+
+/**
+ * MachineByListFile: Находит профиль по имени файла списка работ (первый подходящий).
+ * list.xml без профиля с таким именем считается списком профиля по умолчанию.
+ * @param shortFileName - Имя файла (без пути).
+ * @return MachineProfile - Профиль.
+ * @return bool - false, если файл не является списком работ.
+ */
+func (settings *Settings) MachineByListFile(shortFileName string) (MachineProfile, bool) {
+	for _, profile := range settings.Machines {
+		if strings.EqualFold(shortFileName, profile.ListFile) {
+			return profile, true
+		}
+	}
+	if strings.EqualFold(shortFileName, worklist.ListFileName) {
+		return settings.DefaultMachine(), true
+	}
+	return MachineProfile{}, false
+}
+
+/**
+ * IsListFile: Проверяет, что файл - список работ какого-либо станка (папка уже передана в работу).
+ */
+func (settings *Settings) IsListFile(shortFileName string) bool {
+	_, ok := settings.MachineByListFile(shortFileName)
+	return ok
+}
+
+/**
+ * IsJobFile: Проверяет, что файл - задание для станка: тип файла есть в профиле, в имени нет стоп-слов,
+ * и это не файл списка или файл-метка станка.
+ * @param shortFileName - Имя файла (без пути).
+ */
+func (profile MachineProfile) IsJobFile(shortFileName string) bool {
+	if panel.HasStopWord(shortFileName) || strings.EqualFold(shortFileName, profile.ListFile) || strings.EqualFold(shortFileName, profile.MarkerFile) {
+		return false
+	}
+	ext := strings.ToLower(fileio.GetExtension(shortFileName))
+	for _, profileExt := range profile.FileTypes {
+		if ext == profileExt {
+			return true
+		}
+	}
+	return false
+}
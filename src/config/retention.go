@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// XRetention: Правила хранения архива (TargetDir) в XML; 0 - правило не применяется
+type XRetention struct {
+	CompressAfterMonths int `xml:"CompressAfterMonths,attr"` // Через сколько месяцев заказы упаковываются в zip
+	SummaryAfterMonths  int `xml:"SummaryAfterMonths,attr"`  // Через сколько месяцев от заказа остаётся только метка
+	DeleteAfterMonths   int `xml:"DeleteAfterMonths,attr"`   // Через сколько месяцев папка месяца удаляется
+	ReportsKeepDays     int `xml:"ReportsKeepDays,attr"`     // Сколько дней хранятся отчёты о работе
+}
+
+// RetentionPolicy: Правила хранения архива
+type RetentionPolicy struct {
+	CompressAfter int
+	SummaryAfter  int
+	DeleteAfter   int
+	ReportsDays   int
+}
+
+/**
+ * parseRetention: Преобразует правила хранения из файла настроек во внутреннее представление.
+ * @return error - Ошибка, если срок отрицательный.
+ */
+func parseRetention(xRetention XRetention) (RetentionPolicy, error) {
+	policy := RetentionPolicy{
+		CompressAfter: xRetention.CompressAfterMonths,
+		SummaryAfter:  xRetention.SummaryAfterMonths,
+		DeleteAfter:   xRetention.DeleteAfterMonths,
+		ReportsDays:   xRetention.ReportsKeepDays,
+	}
+	if policy.CompressAfter < 0 || policy.SummaryAfter < 0 || policy.DeleteAfter < 0 || policy.ReportsDays < 0 {
+		return policy, fmt.Errorf(i18n.Tr("сроки хранения не могут быть отрицательными"))
+	}
+	return policy, nil
+}
+
+/**
+ * IsEmpty: Возвращает true, если ни одно правило хранения не задано.
+ */
+func (policy RetentionPolicy) IsEmpty() bool {
+	return policy.CompressAfter == 0 && policy.SummaryAfter == 0 && policy.DeleteAfter == 0 && policy.ReportsDays == 0
+}
@@ -0,0 +1,350 @@
+// Пакет config - настройки программы: чтение ListMaker.xml, шаблон настроек по умолчанию,
+// описание иерархии папок, шаблон пути архива и правила хранения.
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// --- Структуры и типы данных ---
+
+// XMLSettings: Структура для чтения настроек из XML-файла
+type XMLSettings struct {
+	XMLName        xml.Name        `xml:"Root"`
+	IgnoreDirList  XIgnoreDirList  `xml:"IgnoreDirList"`
+	SourceDir      string          `xml:"SourceDir"`
+	TargetDir      string          `xml:"TargetDir"`
+	WorkReportFile string          `xml:"WorkReportFile"`
+	StatisticsFile *string         `xml:"StatisticsFile"`
+	FasadyDirList  *XFasadyDirList `xml:"FasadyDirList"`
+	Hierarchy      *XHierarchy     `xml:"Hierarchy"`
+	FileNames      *XFileNames     `xml:"FileNames"`
+	ListPaths      *XListPaths     `xml:"ListPaths"`
+	Machines       *XMachines      `xml:"Machines"`
+	StaleLists     *XStaleLists    `xml:"StaleLists"`
+	Archive        XArchive        `xml:"Archive"`
+	Retention      XRetention      `xml:"Retention"`
+	Lock           XLock           `xml:"Lock"`
+	Log            XLog            `xml:"Log"`
+	Language       string          `xml:"Language"`
+}
+
+// XIgnoreDirList: Список игнорируемых директорий в XML
+type XIgnoreDirList struct {
+	IgnoreDir []XIgnoreDir `xml:"IgnoreDir"`
+}
+
+// XIgnoreDir: Игнорируемая директория в XML
+type XIgnoreDir struct {
+	Name string `xml:"Name,attr"`
+}
+
+// XFasadyDirList: Правила определения папок с фасадами в XML
+type XFasadyDirList struct {
+	FasadyDir []XFasadyDir `xml:"FasadyDir"`
+}
+
+// XFasadyDir: Шаблон имени папки с фасадами в XML (как в filepath.Match, без учёта регистра)
+type XFasadyDir struct {
+	Pattern string `xml:"Pattern,attr"`
+}
+
+// Settings: Внутреннее представление настроек программы
+type Settings struct {
+	IgnoreList []string // Список имен папок, которые нужно игнорировать
+	DirSource  string   // Исходная папка для сканирования (из файла настроек)
+	DirTarget  string   // Целевая папка
+	FileReport string   // Файл отчета
+	// Имя файлов статистики без расширения (сохраняются .csv и .html), пустое - не сохранять
+	FileStatistics  string
+	FasadyPatterns  []string             // Шаблоны имён папок с фасадами, в которые раскладывается ready_fasady.xml
+	Hierarchy       []HierarchyLevel     // Уровни иерархии папок (заказчик, заказ, проект, материал)
+	NameGrammar     *panel.NameGrammar   // Правила разбора имён файлов (код детали, количество, дата)
+	ListPaths       worklist.PathMapping // Правила записи путей к файлам-заданиям в list.xml
+	Machines        []MachineProfile     // Профили списков работ для станков, пустой - один профиль по умолчанию
+	StaleLists      string               // Что делать, если файлы папки не совпадают со списком работ (StaleLists*)
+	ArchivePath     string               // Шаблон пути заказа в архиве относительно TargetDir
+	ArchiveGrace    int                  // Сколько дней готовый заказ остаётся в исходной папке
+	ArchiveCompress bool                 // Упаковывать заказы в zip-архивы
+	Retention       RetentionPolicy      // Правила хранения архива
+	LockWait        int                  // Сколько секунд ждать освобождения блокировки SourceDir
+	LockStale       int                  // Через сколько часов блокировка считается устаревшей
+	LogMaxSizeKB    int                  // Размер файла журнала, после которого начинается новый
+	LogKeep         int                  // Сколько старых файлов журнала хранить
+}
+
+/**
+ * ReadFromFile: Метод для чтения настроек из XML-файла и заполнения структуры Settings.
+ * @receiver settings - Указатель на структуру Settings для заполнения.
+ * @param fileAbsolutePath - Абсолютный путь к файлу настроек.
+ * @return error - Ошибка при чтении или разборе файла.
+ */
+func (settings *Settings) ReadFromFile(fileAbsolutePath string) error {
+	myFileBytes, err := fileio.ReadFile(fileAbsolutePath)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Не удалось прочитать файл настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+
+	var fileSettings XMLSettings
+	err = xml.Unmarshal(myFileBytes, &fileSettings)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Не удалось разобрать XML из файла настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+
+	// Заполнение внутренней структуры настроек
+	settings.IgnoreList = []string{}
+	for _, el := range fileSettings.IgnoreDirList.IgnoreDir {
+		settings.IgnoreList = append(settings.IgnoreList, el.Name)
+	}
+	settings.DirTarget = fileio.GetAbsoluteFilepath(filepath.Dir(fileAbsolutePath), fileSettings.TargetDir)
+	settings.FileReport = fileSettings.WorkReportFile // Храним только имя файла
+	// В старых файлах настроек элемента нет - используем имя по умолчанию
+	settings.FileStatistics = "Statistics"
+	if fileSettings.StatisticsFile != nil {
+		settings.FileStatistics = strings.TrimSpace(*fileSettings.StatisticsFile)
+	}
+	settings.FasadyPatterns = defaultFasadyPatterns
+	if fileSettings.FasadyDirList != nil {
+		settings.FasadyPatterns = []string{}
+		for _, el := range fileSettings.FasadyDirList.FasadyDir {
+			settings.FasadyPatterns = append(settings.FasadyPatterns, el.Pattern)
+		}
+	}
+
+	settings.Hierarchy, err = parseHierarchy(fileSettings.Hierarchy)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в описании иерархии в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+
+	settings.NameGrammar, err = parseFileNames(fileSettings.FileNames)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в правилах имён файлов в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+
+	settings.ListPaths, err = parseListPaths(fileSettings.ListPaths)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в правилах записи путей в list.xml в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+	settings.Machines, err = parseMachines(fileSettings.Machines, settings.ListPaths)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в описании станков в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+	settings.StaleLists, err = parseStaleLists(fileSettings.StaleLists)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в настройке устаревших списков работ в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+
+	settings.ArchivePath = defaultArchivePath
+	if fileSettings.Archive.PathTemplate != "" {
+		settings.ArchivePath = fileSettings.Archive.PathTemplate
+	}
+	if err = validateArchivePath(settings.ArchivePath); err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в шаблоне пути архива в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+	settings.ArchiveCompress = fileSettings.Archive.Compress
+	settings.ArchiveGrace = fileSettings.Archive.GraceDays
+	if settings.ArchiveGrace < 0 {
+		settings.ArchiveGrace = 0
+	}
+
+	settings.Retention, err = parseRetention(fileSettings.Retention)
+	if err != nil {
+		return fmt.Errorf(i18n.Tr("Ошибка в правилах хранения в файле настроек %s: %w\n"), fileAbsolutePath, err)
+	}
+
+	settings.LockWait = fileSettings.Lock.WaitSeconds
+	if settings.LockWait < 0 {
+		settings.LockWait = 0
+	}
+	settings.LockStale = fileSettings.Lock.StaleHours
+	if settings.LockStale <= 0 {
+		settings.LockStale = defaultLockStaleHours
+	}
+
+	settings.LogMaxSizeKB = fileSettings.Log.MaxSizeKB
+	if settings.LogMaxSizeKB <= 0 {
+		settings.LogMaxSizeKB = logging.DefaultMaxSizeKB
+	}
+	settings.LogKeep = logging.DefaultKeep
+	if fileSettings.Log.Keep > 0 {
+		settings.LogKeep = fileSettings.Log.Keep
+	}
+
+	// Язык из командной строки важнее языка в настройках
+	if !i18n.LanguageFromFlag() && fileSettings.Language != "" {
+		if err := i18n.SetLanguage(fileSettings.Language); err != nil {
+			return fmt.Errorf(i18n.Tr("Ошибка в языке сообщений в файле настроек %s: %w"), fileAbsolutePath, err)
+		}
+	}
+
+	// Валидация настроек (Если SourceDir пуст, станет ".")
+	if fileSettings.SourceDir == "" {
+		settings.DirSource = fileio.GetAbsoluteFilepath(filepath.Dir(fileAbsolutePath), ".")
+	} else {
+		settings.DirSource = fileio.GetAbsoluteFilepath(filepath.Dir(fileAbsolutePath), fileSettings.SourceDir)
+	}
+
+	// Логируем прочитанные настройки
+	logging.Info(i18n.Tr("Настройки прочитаны из файла:"))
+	logging.Info(fmt.Sprintf(i18n.Tr("  SourceDir (из файла): %s"), settings.DirSource))
+	logging.Info(fmt.Sprintf("  TargetDir: %s", settings.DirTarget))
+	logging.Info(fmt.Sprintf("  WorkReportFile: %s", settings.FileReport))
+	logging.Info(fmt.Sprintf("  StatisticsFile: %s", settings.FileStatistics))
+	logging.Info(fmt.Sprintf("  FasadyDirList: %v", settings.FasadyPatterns))
+	logging.Info(fmt.Sprintf("  FileNames: %s", settings.NameGrammar))
+	logging.Info(fmt.Sprintf(i18n.Tr("  ListPaths: относительные %t, разделитель %q, замены %v"), settings.ListPaths.Relative, settings.ListPaths.Separator, settings.ListPaths.Rules))
+	logging.Info(fmt.Sprintf("  StaleLists: %s", settings.StaleLists))
+	logging.Info(fmt.Sprintf(i18n.Tr("  Archive: %s, не ранее чем через %d дн., zip: %t"), settings.ArchivePath, settings.ArchiveGrace, settings.ArchiveCompress))
+	logging.Info(fmt.Sprintf(i18n.Tr("  Retention: zip через %d мес., сводка через %d мес., удаление через %d мес., отчёты %d дн."),
+		settings.Retention.CompressAfter, settings.Retention.SummaryAfter, settings.Retention.DeleteAfter, settings.Retention.ReportsDays))
+	logging.Info(fmt.Sprintf(i18n.Tr("  Lock: ожидание %d сек., устаревает через %d ч."), settings.LockWait, settings.LockStale))
+	logging.Info(fmt.Sprintf(i18n.Tr("  Log: %s, до %d КБ, хранить %d файлов"), logging.FileName, settings.LogMaxSizeKB, settings.LogKeep))
+	logging.Info(fmt.Sprintf("  Language: %s", i18n.Language()))
+	for _, profile := range settings.Machines {
+		logging.Info(fmt.Sprintf(i18n.Tr("  Machine: %s, список %s (%s), файлы %v, папки %q, метка %q"),
+			profile.Name, profile.ListFile, profile.Format, profile.FileTypes, profile.Pattern, profile.MarkerFile))
+	}
+	for _, level := range settings.Hierarchy {
+		logging.Info(fmt.Sprintf(i18n.Tr("  Hierarchy: %s (%s), глубина %d, шаблон %q"), level.Kind, level.Title, level.Depth, level.Pattern))
+	}
+	//logging.Info(fmt.Sprintf("  IgnoreDirList: %v", settings.IgnoreList))
+
+	return nil
+}
+
+/**
+ * IsIgnored: Проверяет, соответствует ли имя директории одному из шаблонов в списке игнорирования.
+ * Сравнивает *имя* папки, а не полный путь.
+ * @receiver settings - Указатель на структуру Settings.
+ * @param dirPath - Полный путь к проверяемой директории.
+ * @return bool - true, если директорию следует игнорировать.
+ * @return error - Ошибка, если путь некорректен или не является директорией.
+ */
+func (settings *Settings) IsIgnored(dirPath string) bool {
+	if fileio.IsValidDir(dirPath) {
+		// Получаем только имя папки из полного пути
+		dirName := filepath.Base(dirPath)
+
+		// Имя папки не может быть пустым или "." или ".."
+		if dirName == "" || dirName == "." || dirName == ".." {
+			return false
+		}
+
+		// Проверяет наличие папки в списке игнорирования
+		return fileio.HasStringInList(dirName, settings.IgnoreList)
+	} else {
+		return false
+	}
+}
+
+/**
+ * WriteDefaultSettingsToFile: Записывает XML-файл с настройками по умолчанию.
+ * @param fileAbsolutePath - Абсолютный путь к файлу для записи.
+ * @return error - Ошибка при записи файла.
+ */
+func WriteDefaultSettingsToFile(fileAbsolutePath string) error {
+	// Шаблон настроек по умолчанию (из первой программы)
+	xmlString := `<?xml version="1.0" encoding="utf-8" ?>
+<Root>
+	<IgnoreDirList>
+		<IgnoreDir Name="#Archive"/>
+		<IgnoreDir Name="#Frezerovki"/>
+		<IgnoreDir Name="#Без_кромок"/>
+		<IgnoreDir Name="#ВЫПОЛНЕННЫЕ"/>
+		<IgnoreDir Name="#ЕВРОЗАПИЛ"/>
+		<IgnoreDir Name="#КОММЕРЦИЯ"/>
+		<IgnoreDir Name="1111"/>
+		<IgnoreDir Name="123"/>
+		<IgnoreDir Name="1234"/>
+		<IgnoreDir Name="12345"/>
+		<IgnoreDir Name=".git"/>
+		<IgnoreDir Name=".svn"/>
+	</IgnoreDirList>
+	<SourceDir>.</SourceDir>
+	<TargetDir>./#ВЫПОЛНЕННЫЕ</TargetDir>
+	<WorkReportFile>WorkReport.txt</WorkReportFile>
+	<StatisticsFile>Statistics</StatisticsFile>
+	<FasadyDirList>
+		<FasadyDir Pattern="*фасад*"/>
+		<FasadyDir Pattern="*fasad*"/>
+	</FasadyDirList>
+	<Hierarchy>
+		<Level Kind="order" Title="Заказ" Depth="1"/>
+		<Level Kind="project" Title="Проект" Depth="2"/>
+		<Level Kind="material" Title="Материал"/>
+	</Hierarchy>
+	<FileNames DateLayout="20060102">
+		<Pattern><![CDATA[^(?P<id>[^_]+)(?:_(?P<qty>[^_]*))?(?:_(?P<material>[^_]*))?(?:_(?P<suffix>.*))?$]]></Pattern>
+		<ReadyPattern><![CDATA[(?:^|_)(?P<date>\d{8})$]]></ReadyPattern>
+	</FileNames>
+	<ListPaths Relative="false" Separator=""/>
+	<Machines>
+		<Machine Name="Раскрой" ListFile="list.xml" Format="worklist">
+			<FileType Code="11" Ext="xml"/>
+			<FileType Code="7" Ext="mpr"/>
+		</Machine>
+	</Machines>
+	<StaleLists Action="warn"/>
+	<Archive PathTemplate="{year}-{month}/{path}" GraceDays="0" Compress="false"/>
+	<Retention CompressAfterMonths="0" SummaryAfterMonths="0" DeleteAfterMonths="0" ReportsKeepDays="0"/>
+	<Lock WaitSeconds="0" StaleHours="12"/>
+	<Log MaxSizeKB="1024" Keep="5"/>
+	<Language>ru</Language>
+</Root>`
+
+	// Создаем директорию для файла настроек, если она не существует
+	parentDir := filepath.Dir(fileAbsolutePath)
+	if _, err := fileio.Stat(parentDir); os.IsNotExist(err) {
+		errMkdir := fileio.MkdirAll(parentDir, 0755)
+		if errMkdir != nil {
+			return fmt.Errorf(i18n.Tr("не удалось создать директорию %s: %w"), parentDir, errMkdir)
+		}
+	}
+
+	// Записываем файл
+	err := fileio.CreateFile(fileAbsolutePath, []byte(xmlString))
+	return err
+}
+
+// Через сколько часов блокировка считается устаревшей, если в настройках не указано иное
+const defaultLockStaleHours = 12
+
+// XLock: Настройки блокировки в XML
+type XLock struct {
+	WaitSeconds int `xml:"WaitSeconds,attr"` // Сколько ждать освобождения блокировки, 0 - не ждать
+	StaleHours  int `xml:"StaleHours,attr"`  // Через сколько часов блокировка считается устаревшей
+}
+
+// XLog: Настройки журнала в XML
+type XLog struct {
+	MaxSizeKB int `xml:"MaxSizeKB,attr"` // Размер файла журнала, после которого начинается новый
+	Keep      int `xml:"Keep,attr"`      // Сколько старых файлов журнала хранить
+}
+
+// шаблоны папок с фасадами, если в файле настроек нет FasadyDirList
+var defaultFasadyPatterns = []string{"*фасад*", "*fasad*"}
+
+/**
+ * IsFasadyDir: Проверяет, соответствует ли имя папки одному из шаблонов папок с фасадами.
+ * @receiver settings - Указатель на структуру Settings.
+ * @param dirName - Имя папки (без пути).
+ * @return bool - true, если папка считается папкой с фасадами.
+ */
+func (settings *Settings) IsFasadyDir(dirName string) bool {
+	for _, pattern := range settings.FasadyPatterns {
+		if ok, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(dirName)); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// действия с устаревшими списками работ (файлы папки не совпадают с FileList)
+const (
+	StaleListsFlag       = "flag"       // отметить папку статусом Иное, обновить вручную (команда refresh-lists)
+	StaleListsWarn       = "warn"       // только предупредить в журнале, папка остаётся в работе
+	StaleListsRegenerate = "regenerate" // пересоздать список, сохранив сделанное количество
+)
+
+// XStaleLists: Действие с устаревшими списками работ в XML
+type XStaleLists struct {
+	Action string `xml:"Action,attr"` // flag, warn (по умолчанию) или regenerate
+}
+
+/**
+ * parseStaleLists: Преобразует действие с устаревшими списками работ из файла настроек.
+ * Без элемента StaleLists об устаревшем списке только предупреждается в журнале: статус ИНОЕ
+ * прерывает обход и отчёт и перемещение в архив для всей SourceDir.
+ * @return error - Ошибка, если действие неизвестно.
+ */
+func parseStaleLists(xStaleLists *XStaleLists) (string, error) {
+	if xStaleLists == nil {
+		return StaleListsWarn, nil
+	}
+	action := strings.ToLower(strings.TrimSpace(xStaleLists.Action))
+	switch action {
+	case "":
+		return StaleListsWarn, nil
+	case StaleListsFlag, StaleListsWarn, StaleListsRegenerate:
+		return action, nil
+	}
+	return "", fmt.Errorf(i18n.Tr("неизвестное действие с устаревшими списками %q"), xStaleLists.Action)
+}
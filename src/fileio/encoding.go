@@ -0,0 +1,214 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// кодировки входных XML-файлов
+const (
+	c_ENC_UTF8    = "utf-8"
+	c_ENC_UTF16LE = "utf-16le"
+	c_ENC_UTF16BE = "utf-16be"
+	c_ENC_CP1251  = "windows-1251"
+)
+
+// TextEncoding: Кодировка файла, чтобы при перезаписи сохранить её как была
+type TextEncoding struct {
+	name  string // c_ENC_*
+	bom   bool   // Файл начинается с метки порядка байтов (BOM)
+	label string // Значение encoding из объявления XML, пустое - объявления нет
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// атрибут encoding в объявлении XML
+var xmlDeclEncoding = regexp.MustCompile(`^(\s*<\?xml[^>]*?encoding\s*=\s*["'])([^"']*)(["'])`)
+
+// Символы CP1251 с кодами 0x80-0xFF (0x98 в кодировке не определён)
+var cp1251High = [128]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+}
+
+// обратная таблица для записи в CP1251
+var cp1251Codes = func() map[rune]byte {
+	codes := make(map[rune]byte, len(cp1251High))
+	for i, r := range cp1251High {
+		if r != 0xFFFD {
+			codes[r] = byte(0x80 + i)
+		}
+	}
+	return codes
+}()
+
+/**
+ * DetectEncoding: Определяет кодировку XML-файла по BOM, объявлению XML и содержимому.
+ * UTF-16 без BOM распознаётся по первому символу "<"; файл без объявления кодировки,
+ * не являющийся корректным UTF-8, считается CP1251.
+ * @param data - Содержимое файла.
+ * @return TextEncoding - Кодировка файла.
+ */
+func DetectEncoding(data []byte) TextEncoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return TextEncoding{name: c_ENC_UTF8, bom: true, label: declaredEncoding(data[len(bomUTF8):])}
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return TextEncoding{name: c_ENC_UTF16LE, bom: true, label: declaredEncoding(decodeUTF16(data[2:], binary.LittleEndian))}
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return TextEncoding{name: c_ENC_UTF16BE, bom: true, label: declaredEncoding(decodeUTF16(data[2:], binary.BigEndian))}
+	case bytes.HasPrefix(data, []byte{'<', 0}):
+		return TextEncoding{name: c_ENC_UTF16LE, label: declaredEncoding(decodeUTF16(data, binary.LittleEndian))}
+	case bytes.HasPrefix(data, []byte{0, '<'}):
+		return TextEncoding{name: c_ENC_UTF16BE, label: declaredEncoding(decodeUTF16(data, binary.BigEndian))}
+	}
+	label := declaredEncoding(data)
+	switch strings.ToLower(label) {
+	case "windows-1251", "cp1251", "cp-1251", "x-cp1251":
+		return TextEncoding{name: c_ENC_CP1251, label: label}
+	case "":
+		if !utf8.Valid(data) {
+			return TextEncoding{name: c_ENC_CP1251}
+		}
+	}
+	return TextEncoding{name: c_ENC_UTF8, label: label}
+}
+
+// Возвращает значение encoding из объявления XML или пустую строку
+func declaredEncoding(data []byte) string {
+	if match := xmlDeclEncoding.FindSubmatch(data); match != nil {
+		return string(match[2])
+	}
+	return ""
+}
+
+/**
+ * DecodeXML: Приводит содержимое XML-файла к UTF-8 для xml.Unmarshal.
+ * BOM удаляется, объявление кодировки заменяется на utf-8.
+ * @param data - Содержимое файла в исходной кодировке.
+ * @return []byte - Содержимое в UTF-8.
+ * @return TextEncoding - Исходная кодировка файла.
+ */
+func DecodeXML(data []byte) ([]byte, TextEncoding) {
+	enc := DetectEncoding(data)
+	var result []byte
+	switch enc.name {
+	case c_ENC_UTF16LE, c_ENC_UTF16BE:
+		body := data
+		if enc.bom {
+			body = data[2:]
+		}
+		var order binary.ByteOrder = binary.LittleEndian
+		if enc.name == c_ENC_UTF16BE {
+			order = binary.BigEndian
+		}
+		result = decodeUTF16(body, order)
+	case c_ENC_CP1251:
+		var sb strings.Builder
+		for _, b := range data {
+			if b < 0x80 {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteRune(cp1251High[b-0x80])
+			}
+		}
+		result = []byte(sb.String())
+	default:
+		result = bytes.TrimPrefix(data, bomUTF8)
+	}
+	if enc.label != "" {
+		result = xmlDeclEncoding.ReplaceAll(result, []byte("${1}utf-8${3}"))
+	}
+	return result, enc
+}
+
+/**
+ * EncodeXML: Переводит XML в UTF-8 в кодировку исходного файла (обратное к DecodeXML).
+ * Объявлению возвращается исходное значение encoding, BOM восстанавливается.
+ * @param data - Содержимое в UTF-8 с объявлением XML.
+ * @param enc - Кодировка исходного файла.
+ * @return []byte - Содержимое в исходной кодировке.
+ * @return error - Ошибка, если символ нельзя записать в CP1251.
+ */
+func EncodeXML(data []byte, enc TextEncoding) ([]byte, error) {
+	label := enc.label
+	if label == "" && enc.name != c_ENC_UTF8 {
+		// без объявления кодировки XML-файл в UTF-16 или CP1251 не будет прочитан другими программами
+		label = enc.name
+		if enc.name != c_ENC_CP1251 {
+			label = "utf-16"
+		}
+	}
+	if label != "" {
+		data = xmlDeclEncoding.ReplaceAll(data, []byte("${1}"+label+"${3}"))
+	}
+	var buf bytes.Buffer
+	switch enc.name {
+	case c_ENC_UTF16LE, c_ENC_UTF16BE:
+		var order binary.ByteOrder = binary.LittleEndian
+		bom := bomUTF16LE
+		if enc.name == c_ENC_UTF16BE {
+			order, bom = binary.BigEndian, bomUTF16BE
+		}
+		if enc.bom {
+			buf.Write(bom)
+		}
+		unit := make([]byte, 2)
+		for _, u := range utf16.Encode([]rune(string(data))) {
+			order.PutUint16(unit, u)
+			buf.Write(unit)
+		}
+	case c_ENC_CP1251:
+		for _, r := range string(data) {
+			if r < 0x80 {
+				buf.WriteByte(byte(r))
+				continue
+			}
+			code, ok := cp1251Codes[r]
+			if !ok {
+				return nil, fmt.Errorf(i18n.Tr("символ %q нельзя записать в кодировке windows-1251"), r)
+			}
+			buf.WriteByte(code)
+		}
+	default:
+		if enc.bom {
+			buf.Write(bomUTF8)
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// Декодирует UTF-16 в UTF-8; нечётный последний байт отбрасывается
+func decodeUTF16(data []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
@@ -0,0 +1,119 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// Кодирует строку в UTF-16 с BOM или без
+func utf16Bytes(text string, order binary.ByteOrder, bom []byte) []byte {
+	result := append([]byte{}, bom...)
+	unit := make([]byte, 2)
+	for _, u := range utf16.Encode([]rune(text)) {
+		order.PutUint16(unit, u)
+		result = append(result, unit...)
+	}
+	return result
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	const utf16Decl = `<?xml version="1.0" encoding="utf-16"?><Root Name="Бок"/>`
+	tests := []struct {
+		name    string
+		data    []byte       // содержимое файла
+		wantEnc TextEncoding // определённая кодировка
+		wantXML string       // содержимое после DecodeXML
+	}{
+		{
+			name:    "CP1251 с объявлением",
+			data:    append([]byte(`<?xml version="1.0" encoding="windows-1251"?><Root Name="`), 0xC1, 0xEE, 0xEA, '"', '/', '>'),
+			wantEnc: TextEncoding{name: c_ENC_CP1251, label: "windows-1251"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+		{
+			name:    "CP1251 без объявления",
+			data:    append([]byte(`<Root Name="`), 0xC1, 0xEE, 0xEA, '"', '/', '>'),
+			wantEnc: TextEncoding{name: c_ENC_CP1251},
+			wantXML: `<Root Name="Бок"/>`,
+		},
+		{
+			name:    "UTF-8 без BOM",
+			data:    []byte(`<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`),
+			wantEnc: TextEncoding{name: c_ENC_UTF8, label: "utf-8"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+		{
+			name:    "UTF-8 с BOM",
+			data:    append(append([]byte{}, bomUTF8...), `<?xml version="1.0" encoding="UTF-8"?><Root Name="Бок"/>`...),
+			wantEnc: TextEncoding{name: c_ENC_UTF8, bom: true, label: "UTF-8"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+		{
+			name:    "UTF-16 LE с BOM",
+			data:    utf16Bytes(utf16Decl, binary.LittleEndian, bomUTF16LE),
+			wantEnc: TextEncoding{name: c_ENC_UTF16LE, bom: true, label: "utf-16"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+		{
+			name:    "UTF-16 LE без BOM",
+			data:    utf16Bytes(utf16Decl, binary.LittleEndian, nil),
+			wantEnc: TextEncoding{name: c_ENC_UTF16LE, label: "utf-16"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+		{
+			name:    "UTF-16 BE с BOM",
+			data:    utf16Bytes(utf16Decl, binary.BigEndian, bomUTF16BE),
+			wantEnc: TextEncoding{name: c_ENC_UTF16BE, bom: true, label: "utf-16"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+		{
+			name:    "UTF-16 BE без BOM",
+			data:    utf16Bytes(utf16Decl, binary.BigEndian, nil),
+			wantEnc: TextEncoding{name: c_ENC_UTF16BE, label: "utf-16"},
+			wantXML: `<?xml version="1.0" encoding="utf-8"?><Root Name="Бок"/>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Action
+			enc := DetectEncoding(tt.data)
+			decoded, decodedEnc := DecodeXML(tt.data)
+			encoded, err := EncodeXML(decoded, decodedEnc)
+			// Assert
+			if enc != tt.wantEnc || decodedEnc != tt.wantEnc {
+				t.Errorf("кодировка: DetectEncoding %+v, DecodeXML %+v; want %+v", enc, decodedEnc, tt.wantEnc)
+			}
+			if string(decoded) != tt.wantXML {
+				t.Errorf("DecodeXML: %q; want %q", decoded, tt.wantXML)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, tt.data) {
+				t.Errorf("EncodeXML: % x; want % x", encoded, tt.data)
+			}
+		})
+	}
+}
+
+// Байт 0x98 в CP1251 не определён: он читается как U+FFFD, а записать его обратно нельзя, как и символ вне CP1251
+func TestEncodingCP1251Unmappable(t *testing.T) {
+	// Arrange
+	data := []byte{'<', 'R', '>', 0x98, '<', '/', 'R', '>'}
+	// Action
+	decoded, enc := DecodeXML(data)
+	_, errUndefined := EncodeXML(decoded, enc)
+	_, errForeign := EncodeXML([]byte("<R>漢</R>"), enc)
+	// Assert
+	if enc.name != c_ENC_CP1251 || !strings.ContainsRune(string(decoded), 0xFFFD) {
+		t.Errorf("DecodeXML: %q (%+v); want U+FFFD в CP1251", decoded, enc)
+	}
+	for _, err := range []error{errUndefined, errForeign} {
+		if err == nil || !strings.Contains(err.Error(), "windows-1251") {
+			t.Errorf("ошибка: %v; want нельзя записать в кодировке windows-1251", err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Пакет fileio - вспомогательные функции работы с файлами: атомарная запись и кодировки XML.
+package fileio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+)
+
+/**
+ * CreateFile: Атомарно записывает файл (см. CreateVerifiedFile) без проверки содержимого.
+ * @param fullFilePath - Полный путь к файлу.
+ * @param data - Содержимое файла.
+ * @return error - Ошибка записи.
+ */
+func CreateFile(fullFilePath string, data []byte) error {
+	return CreateVerifiedFile(fullFilePath, data, nil)
+}
+
+/**
+ * CreateVerifiedFile: Атомарно записывает файл: содержимое пишется во временный файл в той же папке,
+ * сбрасывается на диск, перечитывается и проверяется, и только затем заменяет прежний файл.
+ * При прерывании записи (отключение питания, обрыв сети) прежний файл остаётся целым.
+ * @param fullFilePath - Полный путь к файлу.
+ * @param data - Содержимое файла.
+ * @param verify - Проверка перечитанного содержимого (например, разбор XML), nil - без проверки.
+ * @return error - Ошибка записи или проверки; прежний файл при этом не изменяется.
+ */
+func CreateVerifiedFile(fullFilePath string, data []byte, verify func([]byte) error) error {
+	errWrite := writeFileAtomic(fullFilePath, data, verify)
+	if errWrite != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка записи файла %s: %v"), fullFilePath, errWrite), logging.FieldPath, fullFilePath, logging.FieldAction, "write", logging.FieldError, errWrite)
+	}
+	return errWrite
+}
+
+/**
+ * IsTempFile: Проверяет, что файл - служебный и не относится к заказу: скрытый файл (имя с точки)
+ * или временный *.tmp. Такие файлы остаются, например, от прерванной атомарной записи (см. CreateVerifiedFile).
+ * @param name - Имя файла без пути.
+ * @return bool - true, если файл нужно пропускать при обходе.
+ */
+func IsTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.EqualFold(filepath.Ext(name), ".tmp")
+}
+
+// Записывает файл через временный файл и переименование (см. CreateVerifiedFile)
+func writeFileAtomic(fullFilePath string, data []byte, verify func([]byte) error) error {
+	tmpPath := filepath.Join(filepath.Dir(fullFilePath), fmt.Sprintf(".%s.%d-%d.tmp", filepath.Base(fullFilePath), os.Getpid(), time.Now().UnixNano()))
+	err := WriteFile(tmpPath, data, 0644)
+	if err == nil && verify != nil {
+		// проверяется то, что действительно записано на диск
+		var written []byte
+		if written, err = ReadFile(tmpPath); err == nil {
+			if err = verify(written); err != nil {
+				err = fmt.Errorf(i18n.Tr("записанное содержимое не прошло проверку: %w"), err)
+			}
+		}
+	}
+	if err == nil {
+		err = Rename(tmpPath, fullFilePath)
+	}
+	if err != nil {
+		Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+/**
+ * GetAbsoluteFilepath: Преобразует относительный путь в абсолютный, используя указанную родительскую директорию.
+ * Если путь уже абсолютный, возвращает его без изменений.
+ * @param parent - Родительская директория (абсолютный путь).
+ * @param s - Путь для преобразования (может быть относительным или абсолютным).
+ * @return string - Абсолютный путь.
+ */
+func GetAbsoluteFilepath(parent string, s string) string {
+	if filepath.IsAbs(s) {
+		return filepath.Clean(s) // Возвращаем очищенный абсолютный путь
+	}
+	// Объединяем родительский путь и относительный путь, затем очищаем
+	return filepath.Clean(filepath.Join(parent, s))
+}
+
+/**
+ * GetExtension: Возвращает расширение файла в нижнем регистре без точки.
+ * @param name - Имя файла.
+ * @return string - Расширение файла или пустая строка, если расширения нет.
+ */
+func GetExtension(name string) string {
+	ext := filepath.Ext(name)
+	if len(ext) > 1 {
+		return strings.ToLower(ext[1:]) // Убираем точку и приводим к нижнему регистру
+	}
+	return "" // Пустая строка, если нет расширения
+}
+
+// Проверяет наличие строки в массиве строк
+func HasStringInList(searchFor string, stringList []string) bool {
+	// Приводим массив к нижнему регистру для сравнения без учета регистра
+	stringListLower := make([]string, len(stringList))
+	for i, en := range stringList {
+		stringListLower[i] = strings.ToLower(en)
+	}
+	// Приводим искомую строку к нижнему регистру
+	searchForLower := strings.ToLower(searchFor)
+
+	sort.Strings(stringListLower)
+	pos := sort.SearchStrings(stringListLower, searchForLower)
+	if pos >= len(stringListLower) {
+		return false
+	}
+	res := searchForLower == stringListLower[pos]
+	return res
+}
+
+func IsValidDir(dirPath string) bool {
+	// Проверяем, что это действительно папка
+	fileInfo, err := Stat(dirPath)
+	if err != nil {
+		// Если ошибка связана с тем, что файл/папка не найден, это не ошибка для этой функции
+		if os.IsNotExist(err) {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Папка %s не существует: %v"), dirPath, err), logging.FieldPath, dirPath, logging.FieldAction, "check-dir", logging.FieldError, err)
+			return false // Не существующий путь не может быть пригодным для использования
+		}
+		logging.Warn(fmt.Sprintf(i18n.Tr("Не удалось получить информацию о %s: %v"), dirPath, err), logging.FieldPath, dirPath, logging.FieldAction, "check-dir", logging.FieldError, err) // Другая ошибка Stat
+		return false
+	}
+	if !fileInfo.IsDir() {
+		// Это не папка
+		return false
+	}
+	return true
+}
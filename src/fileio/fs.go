@@ -0,0 +1,406 @@
+package fileio
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileSystem: Файловая система, с которой работает программа.
+// Все пути полные, в формате текущей ОС (filepath).
+type FileSystem interface {
+	ReadDir(name string) ([]fs.DirEntry, error) // Содержимое папки, отсортированное по имени
+	Stat(name string) (fs.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error // Записывает файл и сбрасывает его на диск
+	Rename(oldPath, newPath string) error                       // Переименовывает файл или папку
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(name string) error // Удаляет файл или пустую папку
+	RemoveAll(path string) error
+	Chtimes(name string, modTime time.Time) error // Устанавливает время изменения
+	// Создаёт новый файл с содержимым; если файл уже есть - ошибка fs.ErrExist (файл блокировки)
+	CreateExclusive(name string, data []byte, perm fs.FileMode) error
+}
+
+// текущая файловая система, по умолчанию - файлы на диске
+var currentFS FileSystem = OSFileSystem{}
+
+/**
+ * SetFileSystem: Заменяет файловую систему, с которой работает программа (например, на MemFileSystem в тестах).
+ * @param fileSystem - Новая файловая система.
+ * @return FileSystem - Прежняя файловая система, чтобы её можно было вернуть.
+ */
+func SetFileSystem(fileSystem FileSystem) FileSystem {
+	previous := currentFS
+	currentFS = fileSystem
+	return previous
+}
+
+// Функции ниже вызывают методы текущей файловой системы
+
+func ReadDir(name string) ([]fs.DirEntry, error) { return currentFS.ReadDir(name) }
+func Stat(name string) (fs.FileInfo, error)      { return currentFS.Stat(name) }
+func ReadFile(name string) ([]byte, error)       { return currentFS.ReadFile(name) }
+func WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return currentFS.WriteFile(name, data, perm)
+}
+func CreateExclusive(name string, data []byte, perm fs.FileMode) error {
+	return currentFS.CreateExclusive(name, data, perm)
+}
+func Rename(oldPath, newPath string) error         { return currentFS.Rename(oldPath, newPath) }
+func MkdirAll(path string, perm fs.FileMode) error { return currentFS.MkdirAll(path, perm) }
+func Remove(name string) error                     { return currentFS.Remove(name) }
+func RemoveAll(path string) error                  { return currentFS.RemoveAll(path) }
+func Chtimes(name string, modTime time.Time) error { return currentFS.Chtimes(name, modTime) }
+
+/**
+ * WalkDir: Обходит дерево папок, как filepath.WalkDir, через текущую файловую систему.
+ * @param root - Полный путь к корню обхода.
+ * @param walkFn - Функция, вызываемая для каждого файла и папки; fs.SkipDir пропускает папку.
+ * @return error - Ошибка, возвращённая walkFn.
+ */
+func WalkDir(root string, walkFn fs.WalkDirFunc) error {
+	info, err := Stat(root)
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		err = walkDir(root, fs.FileInfoToDirEntry(info), walkFn)
+	}
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// Рекурсивная часть WalkDir
+func walkDir(dirPath string, entry fs.DirEntry, walkFn fs.WalkDirFunc) error {
+	if err := walkFn(dirPath, entry, nil); err != nil || !entry.IsDir() {
+		if err == fs.SkipDir && entry.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := ReadDir(dirPath)
+	if err != nil {
+		// повторный вызов сообщает об ошибке чтения папки
+		if err = walkFn(dirPath, entry, err); err != nil {
+			if err == fs.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, child := range entries {
+		if err := walkDir(filepath.Join(dirPath, child.Name()), child, walkFn); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * OpenZip: Открывает zip-архив для чтения; архив читается в память целиком.
+ * @param zipPath - Полный путь к zip-файлу.
+ * @return *zip.Reader - Содержимое архива.
+ * @return error - Ошибка чтения или формата архива.
+ */
+func OpenZip(zipPath string) (*zip.Reader, error) {
+	data, err := ReadFile(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// --- Файлы на диске ---
+
+// OSFileSystem: Файловая система ОС (пакет os)
+type OSFileSystem struct{}
+
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (OSFileSystem) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (OSFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+func (OSFileSystem) Remove(name string) error    { return os.Remove(name) }
+func (OSFileSystem) RemoveAll(path string) error { return os.RemoveAll(path) }
+func (OSFileSystem) Chtimes(name string, modTime time.Time) error {
+	return os.Chtimes(name, modTime, modTime)
+}
+
+// Записывает файл и сбрасывает его на диск
+func (OSFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// Создаёт новый файл (O_EXCL - проверка и создание одной операцией ОС) и сбрасывает его на диск;
+// недописанный файл удаляется
+func (OSFileSystem) CreateExclusive(name string, data []byte, perm fs.FileMode) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(name)
+	}
+	return err
+}
+
+// Переименовывает файл или папку и сохраняет изменение записи папки на диске
+func (OSFileSystem) Rename(oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	// в Windows папку открыть так нельзя, тогда запись папки сохранит сама ОС
+	if dirFile, errDir := os.Open(filepath.Dir(newPath)); errDir == nil {
+		dirFile.Sync()
+		dirFile.Close()
+	}
+	return nil
+}
+
+// --- Файлы в памяти ---
+
+// MemFileSystem: Файловая система в памяти - для тестов и проверки алгоритма без настоящих папок
+type MemFileSystem struct {
+	nodes map[string]*memNode // Файлы и папки по очищенному полному пути
+}
+
+// Файл или папка в памяти
+type memNode struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+/**
+ * NewMemFileSystem: Создаёт пустую файловую систему в памяти.
+ * @return *MemFileSystem - Файловая система, в которой есть только корневая папка.
+ */
+func NewMemFileSystem() *MemFileSystem {
+	memFS := &MemFileSystem{nodes: make(map[string]*memNode)}
+	memFS.nodes[string(filepath.Separator)] = &memNode{mode: fs.ModeDir | 0777, modTime: time.Now()}
+	return memFS
+}
+
+// Возвращает узел по пути или ошибку fs.ErrNotExist
+func (memFS *MemFileSystem) node(op string, name string) (*memNode, error) {
+	node, ok := memFS.nodes[memPath(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+// Приводит путь к ключу карты узлов
+func memPath(name string) string {
+	cleaned := filepath.Clean(name)
+	if vol := filepath.VolumeName(cleaned); vol != "" {
+		cleaned = cleaned[len(vol):]
+	}
+	if !filepath.IsAbs(cleaned) {
+		cleaned = string(filepath.Separator) + cleaned
+	}
+	return filepath.Clean(cleaned)
+}
+
+// Проверяет, что родительская папка существует
+func (memFS *MemFileSystem) checkParent(op string, name string) error {
+	parent, err := memFS.node(op, filepath.Dir(memPath(name)))
+	if err != nil {
+		return err
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: errors.New("not a directory")}
+	}
+	return nil
+}
+
+// Возвращает пути всех узлов внутри папки (на любой глубине)
+func (memFS *MemFileSystem) descendants(dirKey string) []string {
+	prefix := dirKey + string(filepath.Separator)
+	if dirKey == string(filepath.Separator) {
+		prefix = dirKey
+	}
+	var keys []string
+	for key := range memFS.nodes {
+		if key != dirKey && len(key) > len(prefix) && key[:len(prefix)] == prefix {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (memFS *MemFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	node, err := memFS.node("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	dirKey := memPath(name)
+	var entries []fs.DirEntry
+	for _, key := range memFS.descendants(dirKey) {
+		if filepath.Dir(key) == dirKey {
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: filepath.Base(key), node: memFS.nodes[key]}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (memFS *MemFileSystem) Stat(name string) (fs.FileInfo, error) {
+	node, err := memFS.node("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return memFileInfo{name: filepath.Base(memPath(name)), node: node}, nil
+}
+
+func (memFS *MemFileSystem) ReadFile(name string) ([]byte, error) {
+	node, err := memFS.node("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return bytes.Clone(node.data), nil
+}
+
+func (memFS *MemFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := memFS.checkParent("open", name); err != nil {
+		return err
+	}
+	if node, ok := memFS.nodes[memPath(name)]; ok {
+		if node.mode.IsDir() {
+			return &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+		}
+		node.data = bytes.Clone(data)
+		node.modTime = time.Now()
+		return nil
+	}
+	memFS.nodes[memPath(name)] = &memNode{data: bytes.Clone(data), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (memFS *MemFileSystem) CreateExclusive(name string, data []byte, perm fs.FileMode) error {
+	if _, ok := memFS.nodes[memPath(name)]; ok {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	}
+	return memFS.WriteFile(name, data, perm)
+}
+
+func (memFS *MemFileSystem) Rename(oldPath, newPath string) error {
+	oldKey, newKey := memPath(oldPath), memPath(newPath)
+	node, err := memFS.node("rename", oldPath)
+	if err != nil {
+		return err
+	}
+	if err := memFS.checkParent("rename", newPath); err != nil {
+		return err
+	}
+	if target, ok := memFS.nodes[newKey]; ok && (target.mode.IsDir() || node.mode.IsDir()) {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: fs.ErrExist}
+	}
+	if node.mode.IsDir() {
+		for _, key := range memFS.descendants(oldKey) {
+			memFS.nodes[newKey+key[len(oldKey):]] = memFS.nodes[key]
+			delete(memFS.nodes, key)
+		}
+	}
+	delete(memFS.nodes, oldKey)
+	memFS.nodes[newKey] = node
+	return nil
+}
+
+func (memFS *MemFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	key := memPath(path)
+	if node, ok := memFS.nodes[key]; ok {
+		if !node.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if parent := filepath.Dir(key); parent != key {
+		if err := memFS.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	memFS.nodes[key] = &memNode{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (memFS *MemFileSystem) Remove(name string) error {
+	node, err := memFS.node("remove", name)
+	if err != nil {
+		return err
+	}
+	key := memPath(name)
+	if node.mode.IsDir() && len(memFS.descendants(key)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	delete(memFS.nodes, key)
+	return nil
+}
+
+func (memFS *MemFileSystem) RemoveAll(path string) error {
+	key := memPath(path)
+	for _, child := range memFS.descendants(key) {
+		delete(memFS.nodes, child)
+	}
+	if key != string(filepath.Separator) {
+		delete(memFS.nodes, key)
+	}
+	return nil
+}
+
+func (memFS *MemFileSystem) Chtimes(name string, modTime time.Time) error {
+	node, err := memFS.node("chtimes", name)
+	if err != nil {
+		return err
+	}
+	node.modTime = modTime
+	return nil
+}
+
+// Сведения о файле в памяти (fs.FileInfo)
+type memFileInfo struct {
+	name string
+	node *memNode
+}
+
+func (info memFileInfo) Name() string       { return info.name }
+func (info memFileInfo) Size() int64        { return int64(len(info.node.data)) }
+func (info memFileInfo) Mode() fs.FileMode  { return info.node.mode }
+func (info memFileInfo) ModTime() time.Time { return info.node.modTime }
+func (info memFileInfo) IsDir() bool        { return info.node.mode.IsDir() }
+func (info memFileInfo) Sys() any           { return nil }
@@ -0,0 +1,116 @@
+package fileio
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+// Подключает на время теста файловую систему в памяти
+func useMem(t *testing.T) *MemFileSystem {
+	memFS := NewMemFileSystem()
+	previous := SetFileSystem(memFS)
+	t.Cleanup(func() { SetFileSystem(previous) })
+	return memFS
+}
+
+// Возвращает пути всех файлов и папок дерева в порядке обхода
+func walkPaths(t *testing.T, root string) []string {
+	var paths []string
+	err := WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestMemFileSystem(t *testing.T) {
+	// Arrange
+	useMem(t)
+	if err := MkdirAll("/src/Заказ/Кухня", 0777); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	errWrite := WriteFile("/src/Заказ/Кухня/1_2_Бок.xml", []byte("<Root/>"), 0644)
+	errNoParent := WriteFile("/src/Нет/1_2_Бок.xml", nil, 0644)
+	data, errRead := ReadFile("/src/Заказ/Кухня/1_2_Бок.xml")
+	// Assert
+	if errWrite != nil || errRead != nil || string(data) != "<Root/>" {
+		t.Errorf("запись и чтение: %v, %v, %q", errWrite, errRead, data)
+	}
+	if !errors.Is(errNoParent, fs.ErrNotExist) {
+		t.Errorf("запись в несуществующую папку: %v; want fs.ErrNotExist", errNoParent)
+	}
+	if err := Remove("/src/Заказ"); err == nil {
+		t.Errorf("удалена непустая папка")
+	}
+	if info, err := Stat("/src/Заказ"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(/src/Заказ) = %v, %v", info, err)
+	}
+}
+
+func TestMemFileSystemRename(t *testing.T) {
+	// Arrange
+	useMem(t)
+	MkdirAll("/src/Заказ/Кухня", 0777)
+	MkdirAll("/done/2025-06", 0777)
+	WriteFile("/src/Заказ/Кухня/1_2_Бок.xml", []byte("1"), 0644)
+	WriteFile("/src/Заказ/order_ready_20250601.xml", []byte("2"), 0644)
+	// Action
+	err := Rename("/src/Заказ", "/done/2025-06/Заказ")
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/done", "/done/2025-06", "/done/2025-06/Заказ", "/done/2025-06/Заказ/order_ready_20250601.xml",
+		"/done/2025-06/Заказ/Кухня", "/done/2025-06/Заказ/Кухня/1_2_Бок.xml", "/src"}
+	if got := walkPaths(t, "/"); !reflect.DeepEqual(got[1:], want) {
+		t.Errorf("после переименования:\ngot %q;\nwant %q", got[1:], want)
+	}
+}
+
+func TestCreateVerifiedFile(t *testing.T) {
+	// Arrange
+	useMem(t)
+	MkdirAll("/src", 0777)
+	CreateFile("/src/list.xml", []byte("old"))
+	// Action
+	err := CreateVerifiedFile("/src/list.xml", []byte("new"), func([]byte) error { return errors.New("повреждён") })
+	// Assert
+	if err == nil {
+		t.Errorf("ошибка проверки не возвращена")
+	}
+	if data, _ := ReadFile("/src/list.xml"); string(data) != "old" {
+		t.Errorf("прежний файл изменён: %q", data)
+	}
+	if got := walkPaths(t, "/src"); !reflect.DeepEqual(got, []string{"/src", "/src/list.xml"}) {
+		t.Errorf("остались временные файлы: %q", got)
+	}
+}
+
+// Существующий файл не перезаписывается (файл блокировки)
+func TestCreateExclusive(t *testing.T) {
+	// Arrange
+	useMem(t)
+	MkdirAll("/src", 0777)
+	// Action
+	errFirst := CreateExclusive("/src/ListMaker.lock", []byte("1"), 0666)
+	errSecond := CreateExclusive("/src/ListMaker.lock", []byte("2"), 0666)
+	// Assert
+	if errFirst != nil {
+		t.Fatal(errFirst)
+	}
+	if !errors.Is(errSecond, fs.ErrExist) {
+		t.Errorf("повторное создание: %v; want fs.ErrExist", errSecond)
+	}
+	if data, _ := ReadFile("/src/ListMaker.lock"); string(data) != "1" {
+		t.Errorf("файл перезаписан: %q", data)
+	}
+}
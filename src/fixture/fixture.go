@@ -0,0 +1,223 @@
+// Пакет fixture - построение тестовых деревьев папок заказов (заказчик → заказ → проект → раскрой)
+// с XML-файлами деталей, MPR-файлами, list.xml, метками готовности и файлами фасадов,
+// а также приведение результатов к виду для сравнения с эталонами (Normalize, см. fixturetest).
+package fixture
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/walker"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// Tree: Дерево папок с корнем Root; файлы пишутся через текущую файловую систему fileio
+type Tree struct {
+	Root string
+}
+
+/**
+ * New: Создаёт корневую папку дерева.
+ * @param root - Полный путь к корню.
+ * @return Tree - Дерево для добавления папок и файлов.
+ */
+func New(root string) Tree {
+	must(fileio.MkdirAll(root, 0777))
+	return Tree{Root: root}
+}
+
+// Path: Возвращает полный путь по пути относительно корня (разделитель "/")
+func (tree Tree) Path(rel string) string {
+	return filepath.Join(tree.Root, filepath.FromSlash(rel))
+}
+
+// Dir: Создаёт папку (вместе с вышестоящими)
+func (tree Tree) Dir(rel string) {
+	must(fileio.MkdirAll(tree.Path(rel), 0777))
+}
+
+// Создаёт папку файла и записывает файл
+func (tree Tree) write(rel string, data []byte) {
+	tree.Dir(filepath.ToSlash(filepath.Dir(filepath.FromSlash(rel))))
+	must(fileio.WriteFile(tree.Path(rel), data, 0644))
+}
+
+// PanelXML: Возвращает содержимое XML-файла детали с одной панелью
+func PanelXML(length, width float64, count int) []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="utf-8" ?>
+<Root>
+	<Project Name="" Flag="SWJ008">
+		<Panels>
+			<Panel ID="1" Name="" Width="%.3f" Length="%.3f" Material="" Thickness="16.000" Count="%d">
+			</Panel>
+		</Panels>
+	</Project>
+</Root>
+`, width, length, count))
+}
+
+/**
+ * Panel: Записывает XML-файл детали.
+ * @param rel - Путь к файлу относительно корня, например "Иванов/Кухня/ЛДСП/1.2_3_Дверь.xml".
+ * @param length - Длина панели, мм.
+ * @param width - Ширина панели, мм.
+ * @param count - Количество панелей.
+ */
+func (tree Tree) Panel(rel string, length, width float64, count int) {
+	tree.write(rel, PanelXML(length, width, count))
+}
+
+// MPR: Записывает MPR-файл (программа для станка) с минимальным содержимым
+func (tree Tree) MPR(rel string) {
+	tree.write(rel, []byte("[H\nVERSION=\"4.0 Alpha\"\n"))
+}
+
+/**
+ * List: Записывает list.xml в папку - как будто папка уже передана в работу.
+ * @param relDir - Путь к папке относительно корня.
+ * @param files - Имена файлов-заданий в папке.
+ */
+func (tree Tree) List(relDir string, files ...string) {
+	var fullNames []string
+	for _, name := range files {
+		fullNames = append(fullNames, tree.Path(relDir+"/"+name))
+	}
+	tree.write(relDir+"/"+worklist.ListFileName, []byte(worklist.GetOutputXML(fullNames, worklist.DefaultFileFormats, worklist.PathMapping{}, nil)))
+}
+
+// Ready: Записывает выполненный плейлист ready_yyyymmdd.xml (date в формате yyyymmdd)
+func (tree Tree) Ready(relDir string, date string) {
+	tree.write(relDir+"/ready_"+date+".xml", []byte(`<?xml version="1.0" encoding="utf-8" ?>`+"\n<WorkList/>\n"))
+}
+
+// ReadyFasady: Записывает выполненный плейлист фасадов ready_fasady.xml с указанным временем изменения
+func (tree Tree) ReadyFasady(relDir string, modTime time.Time) {
+	rel := relDir + "/ready_fasady.xml"
+	tree.write(rel, []byte(`<?xml version="1.0" encoding="utf-8" ?>`+"\n<WorkList/>\n"))
+	must(fileio.Chtimes(tree.Path(rel), modTime))
+}
+
+/**
+ * OrderMarker: Записывает метку order_ready_yyyymmdd.xml с отчётом о папке.
+ * @param relDir - Путь к папке относительно корня.
+ * @param item - Отчёт о папке; дата в имени метки берётся из item.DateReady (yyyy-mm-dd).
+ */
+func (tree Tree) OrderMarker(relDir string, item walker.ReportObj) {
+	tree.Dir(relDir)
+	name := "order_ready_" + strings.ReplaceAll(item.DateReady, "-", "") + ".xml"
+	must(item.WriteReportToFile(tree.Path(relDir + "/" + name)))
+}
+
+/**
+ * Settings: Записывает файл настроек с SourceDir и TargetDir и возвращает путь к нему.
+ * @param rel - Путь к файлу настроек относительно корня.
+ * @param sourceDir - Значение SourceDir (относительно папки файла настроек или полный путь).
+ * @param targetDir - Значение TargetDir.
+ * @param extra - Дополнительные элементы настроек (XML), добавляемые в Root.
+ * @return string - Полный путь к файлу настроек.
+ */
+func (tree Tree) Settings(rel string, sourceDir string, targetDir string, extra ...string) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="utf-8" ?>` + "\n<Root>\n")
+	sb.WriteString("\t<IgnoreDirList><IgnoreDir Name=\"#ВЫПОЛНЕННЫЕ\"/></IgnoreDirList>\n")
+	fmt.Fprintf(&sb, "\t<SourceDir>%s</SourceDir>\n\t<TargetDir>%s</TargetDir>\n", sourceDir, targetDir)
+	sb.WriteString("\t<WorkReportFile>WorkReport.txt</WorkReportFile>\n")
+	for _, element := range extra {
+		sb.WriteString("\t" + element + "\n")
+	}
+	sb.WriteString("</Root>\n")
+	tree.write(rel, []byte(sb.String()))
+	return tree.Path(rel)
+}
+
+// Spec: Описание синтетического дерева для Generate
+type Spec struct {
+	Customers int   // Заказчиков
+	Orders    int   // Заказов у заказчика
+	Projects  int   // Проектов в заказе
+	Materials int   // Раскроев (папок материалов) в проекте
+	Fasady    bool  // Добавлять в заказы проект "Фасады" с ready_fasady.xml
+	Seed      int64 // Начальное значение генератора, одно и то же значение даёт одно и то же дерево
+}
+
+// состояния папки раскроя в синтетическом дереве
+const (
+	c_CUT_READY = iota // выполнен: ready_yyyymmdd.xml
+	c_CUT_NEW          // новые файлы-задания, list.xml ещё нет
+	c_CUT_LIST         // передан в работу: есть list.xml
+	c_CUT_COUNT
+)
+
+var (
+	customerNames = []string{"Иванов", "Петров", "Сидоров", "Кузнецова", "Смирнов", "Попова", "Васильев", "Соколова"}
+	projectNames  = []string{"Кухня", "Шкаф", "Прихожая", "Детская", "Гардероб", "Ванная"}
+	materialNames = []string{"ЛДСП Белый", "ЛДСП Дуб", "ЛДСП Серый", "МДФ", "ХДФ"}
+	detailNames   = []string{"Бок", "Полка", "Дверь", "Крыша", "Дно", "Цоколь", "Фасад"}
+)
+
+/**
+ * Generate: Строит синтетическое дерево заказов: заказчик/заказ/проект/материал.
+ * Папки материалов получают XML-файлы деталей и MPR-файлы и случайное состояние:
+ * выполнен (ready_yyyymmdd.xml), новые задания или уже в работе (list.xml).
+ * @param root - Полный путь к корню дерева (SourceDir).
+ * @param spec - Размеры дерева и начальное значение генератора.
+ * @return Tree - Построенное дерево.
+ */
+func Generate(root string, spec Spec) Tree {
+	tree := New(root)
+	random := rand.New(rand.NewSource(spec.Seed))
+	baseDate := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for c := 0; c < spec.Customers; c++ {
+		customer := pick(customerNames, c)
+		for o := 0; o < spec.Orders; o++ {
+			order := fmt.Sprintf("%s/Заказ %d", customer, o+1)
+			for p := 0; p < spec.Projects; p++ {
+				project := order + "/" + pick(projectNames, p)
+				for m := 0; m < spec.Materials; m++ {
+					cut := project + "/" + pick(materialNames, m)
+					var files []string
+					for d := 0; d < 1+random.Intn(4); d++ {
+						name := fmt.Sprintf("%d.%d_%d_%s.xml", p+1, d+1, 1+random.Intn(4), detailNames[random.Intn(len(detailNames))])
+						tree.Panel(cut+"/"+name, float64(300+random.Intn(2000)), float64(200+random.Intn(600)), 1)
+						files = append(files, name)
+					}
+					if random.Intn(3) == 0 {
+						name := fmt.Sprintf("%d.%d_1_Фреза.mpr", p+1, len(files)+1)
+						tree.MPR(cut + "/" + name)
+						files = append(files, name)
+					}
+					switch random.Intn(c_CUT_COUNT) {
+					case c_CUT_READY:
+						tree.Ready(cut, baseDate.AddDate(0, 0, random.Intn(60)).Format("20060102"))
+					case c_CUT_LIST:
+						tree.List(cut, files...)
+					}
+				}
+			}
+			if spec.Fasady {
+				tree.Panel(order+"/Фасады/1_2_Фасад.xml", 716, 396, 2)
+				tree.ReadyFasady(order, baseDate.AddDate(0, 0, random.Intn(60)))
+			}
+		}
+	}
+	return tree
+}
+
+// Возвращает имя из списка по номеру; при исчерпании списка добавляет номер
+func pick(names []string, i int) string {
+	if i < len(names) {
+		return names[i]
+	}
+	return fmt.Sprintf("%s %d", names[i%len(names)], i/len(names)+1)
+}
+
+// Прерывает построение дерева при ошибке записи
+func must(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("fixture: %v", err))
+	}
+}
@@ -0,0 +1,58 @@
+// Пакет fixturetest - помощники тестов: файловая система в памяти на время теста
+// и сравнение результатов с эталонными файлами (golden). Вынесены из fixture,
+// чтобы пакет testing не попадал в программу gentree.
+package fixturetest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+)
+
+// Переменная окружения, при которой эталонные файлы перезаписываются: UPDATE_GOLDEN=1 go test ./...
+const c_UPDATE_ENV = "UPDATE_GOLDEN"
+
+/**
+ * Golden: Сравнивает результат с эталонным файлом testdata/<name>.golden на диске.
+ * Эталоны всегда читаются с диска, даже если тест работает с файлами в памяти.
+ * @param t - Тест.
+ * @param name - Имя эталона без расширения.
+ * @param got - Полученный результат (нормализуется, см. Normalize).
+ */
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	got = fixture.Normalize(got)
+	goldenPath := filepath.Join("testdata", name+".golden")
+	if os.Getenv(c_UPDATE_ENV) != "" {
+		if err := os.MkdirAll("testdata", 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("нет эталона %s (%v), создайте его: %s=1 go test", goldenPath, err, c_UPDATE_ENV)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s не совпадает с эталоном\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+/**
+ * Mem: Подключает на время теста файловую систему в памяти и возвращает её.
+ * @param t - Тест; после его завершения возвращается прежняя файловая система.
+ * @return *fileio.MemFileSystem - Файловая система в памяти.
+ */
+func Mem(t testing.TB) *fileio.MemFileSystem {
+	memFS := fileio.NewMemFileSystem()
+	previous := fileio.SetFileSystem(memFS)
+	t.Cleanup(func() { fileio.SetFileSystem(previous) })
+	return memFS
+}
@@ -0,0 +1,74 @@
+// Программа gentree строит синтетическое дерево заказов для ручной проверки ListMaker:
+//
+//	go run ./fixture/gentree [-seed 1] [-customers 4] [-orders 2] [-projects 2] [-materials 3] <папка>
+//
+// Папка очищается и заполняется заново, рядом с ней записывается listMaker_settings.xml.
+// Очищается только пустая папка или папка, построенная gentree (с файлом .gentree);
+// другую непустую папку gentree удаляет только с ключом -force.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ProOwler/ListMaker/fixture"
+)
+
+// Файл-метка папки, построенной gentree; точка в начале имени - обход ListMaker его пропускает
+const c_MARKER_FILE = ".gentree"
+
+func main() {
+	var spec fixture.Spec
+	flag.Int64Var(&spec.Seed, "seed", 1, "начальное значение генератора")
+	flag.IntVar(&spec.Customers, "customers", 4, "заказчиков")
+	flag.IntVar(&spec.Orders, "orders", 2, "заказов у заказчика")
+	flag.IntVar(&spec.Projects, "projects", 2, "проектов в заказе")
+	flag.IntVar(&spec.Materials, "materials", 3, "раскроев в проекте")
+	flag.BoolVar(&spec.Fasady, "fasady", true, "добавлять в заказы фасады с ready_fasady.xml")
+	force := flag.Bool("force", false, "очистить непустую папку, построенную не gentree")
+	flag.Parse()
+	if flag.NArg() != 1 || flag.Arg(0) == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	sourceDir, err := filepath.Abs(flag.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if !*force && !isGenerated(sourceDir) {
+		fmt.Printf("папка %s не пуста и построена не gentree; чтобы удалить её содержимое, укажите -force\n", sourceDir)
+		os.Exit(1)
+	}
+	if err := os.RemoveAll(sourceDir); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	tree := fixture.Generate(sourceDir, spec)
+	if err := os.WriteFile(filepath.Join(sourceDir, c_MARKER_FILE), nil, 0644); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	parent := fixture.Tree{Root: filepath.Dir(sourceDir)}
+	parent.Dir("done")
+	fmt.Println(parent.Settings("listMaker_settings.xml", "./"+filepath.Base(sourceDir), "./done"))
+	fmt.Println(tree.Root)
+}
+
+// Проверяет, что папку можно очистить: её нет, она пуста или построена gentree
+func isGenerated(dirPath string) bool {
+	entries, err := os.ReadDir(dirPath)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	if len(entries) == 0 {
+		return true
+	}
+	_, err = os.Stat(filepath.Join(dirPath, c_MARKER_FILE))
+	return err == nil
+}
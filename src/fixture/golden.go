@@ -0,0 +1,24 @@
+package fixture
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+)
+
+// атрибуты, меняющиеся от запуска к запуску
+var volatileAttrs = regexp.MustCompile(`(Created)="[^"]*"`)
+
+/**
+ * Normalize: Приводит результат к виду, не зависящему от запуска и ОС:
+ * время создания файлов заменяется на "*", разделители путей - на "/".
+ * @param data - Содержимое файла или отчёта.
+ * @return []byte - Нормализованное содержимое.
+ */
+func Normalize(data []byte) []byte {
+	data = volatileAttrs.ReplaceAll(data, []byte(`$1="*"`))
+	if filepath.Separator != '/' {
+		data = bytes.ReplaceAll(data, []byte{filepath.Separator}, []byte("/"))
+	}
+	return data
+}
@@ -0,0 +1,342 @@
+// Пакет i18n - каталог сообщений программы на русском и английском языках.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// языки сообщений программы
+const (
+	c_LANG_RU = "ru"
+	c_LANG_EN = "en"
+)
+
+// Язык сообщений; задаётся ключом --lang или элементом Language в настройках
+var appLang = c_LANG_RU
+
+// Язык, заданный ключом командной строки; пустой - берётся из настроек
+var langFromFlag string
+
+/**
+ * Tr: Переводит сообщение на язык программы.
+ * Ключ каталога - исходный русский текст без начальных и конечных пробелов и переводов строк,
+ * они переносятся в перевод как есть. Сообщение без перевода возвращается без изменений.
+ * @param message - Сообщение на русском языке (строка формата для fmt).
+ * @return string - Сообщение на языке appLang.
+ */
+func Tr(message string) string {
+	catalog, ok := messageCatalogs[appLang]
+	if !ok {
+		return message
+	}
+	core := strings.TrimSpace(message)
+	translated, ok := catalog[core]
+	if !ok || core == "" {
+		return message
+	}
+	start := strings.Index(message, core)
+	return message[:start] + translated + message[start+len(core):]
+}
+
+// Переводит каждый элемент списка (заголовки таблиц)
+func TrAll(messages []string) []string {
+	result := make([]string, len(messages))
+	for i, message := range messages {
+		result[i] = Tr(message)
+	}
+	return result
+}
+
+/**
+ * Language: Возвращает код текущего языка сообщений (ru, en).
+ */
+func Language() string {
+	return appLang
+}
+
+/**
+ * LanguageFromFlag: Проверяет, что язык задан ключом командной строки и настройки его не меняют.
+ */
+func LanguageFromFlag() bool {
+	return langFromFlag != ""
+}
+
+/**
+ * SetLanguage: Устанавливает язык сообщений.
+ * @param lang - Код языка: ru или en.
+ * @return error - Ошибка, если язык не поддерживается.
+ */
+func SetLanguage(lang string) error {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang != c_LANG_RU {
+		if _, ok := messageCatalogs[lang]; !ok {
+			return fmt.Errorf("unsupported language %q (ru, en)", lang)
+		}
+	}
+	appLang = lang
+	return nil
+}
+
+/**
+ * ParseFlag: Убирает из аргументов командной строки ключ --lang=<код> (или --lang <код>) и применяет его.
+ * Язык из командной строки важнее указанного в настройках.
+ * @param args - Аргументы командной строки без имени программы.
+ * @return []string - Остальные аргументы.
+ */
+func ParseFlag(args []string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		lang, found := strings.CutPrefix(args[i], "--lang=")
+		if !found && args[i] == "--lang" && i+1 < len(args) {
+			i++
+			lang, found = args[i], true
+		}
+		if !found {
+			rest = append(rest, args[i])
+			continue
+		}
+		if err := SetLanguage(lang); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		langFromFlag = appLang
+	}
+	return rest
+}
+
+// Переводы сообщений; русский текст - ключ, для русского языка каталог не нужен
+var messageCatalogs = map[string]map[string]string{
+	c_LANG_EN: {
+		"<p>Заказов: %d, проектов: %d, средний срок: %s дн.</p>":                                    "<p>Orders: %d, projects: %d, average lead time: %s days</p>",
+		"<p>Ожидают: %d, требуют участия: %d</p>":                                                   "<p>Pending: %d, need attention: %d</p>",
+		"Archive: %s, не ранее чем через %d дн., zip: %t":                                           "Archive: %s, not earlier than %d days, zip: %t",
+		"Hierarchy: %s (%s), глубина %d, шаблон %q":                                                 "Hierarchy: %s (%s), depth %d, pattern %q",
+		"ListPaths: относительные %t, разделитель %q, замены %v":                                    "ListPaths: relative %t, separator %q, rules %v",
+		"Lock: ожидание %d сек., устаревает через %d ч.":                                            "Lock: wait %d s, stale after %d h",
+		"Log: %s, до %d КБ, хранить %d файлов":                                                      "Log: %s, up to %d KB, keep %d files",
+		"Machine: %s, список %s (%s), файлы %v, папки %q, метка %q":                                 "Machine: %s, list %s (%s), files %v, folders %q, marker %q",
+		"Retention: zip через %d мес., сводка через %d мес., удаление через %d мес., отчёты %d дн.": "Retention: zip after %d months, summary after %d months, delete after %d months, reports %d days",
+		"SourceDir (из файла): %s":                                                                  "SourceDir (from file): %s",
+		"Будет удалено отчётов о работе старше %s: %d":                                              "Work reports older than %s to be deleted: %d",
+		"В %s нет сохранённых отчётов":                                                              "No saved reports in %s",
+		"В папке %s нет заказов":                                                                    "Folder %s has no orders",
+		"В папке с фасадами %s есть список работ, файл %s в неё не скопирован":                      "Facade folder %s has a work list, %s not copied there",
+		"Выполнение завершено. Затрачено времени: %.6f сек":                                         "Done. Elapsed time: %.6f s",
+		"Готов":           "Ready",
+		"Дата готовности": "Ready date",
+		"Для закрытия окна нажмите Enter":                         "Press Enter to close the window",
+		"Для сравнения нужно хотя бы два сохранённых отчёта в %s": "At least two saved reports in %s are needed for comparison",
+		"Журнал не ведётся: %v":                                   "Log file is disabled: %v",
+		"Заказ":                                                   "Order",
+		"Заказ %s готов %d дн. назад, будет перемещён в архив через %d дн.": "Order %s was ready %d days ago, will be archived in %d days",
+		"Заказ %s не перемещён в архив: %s уже существует":                  "Order %s not archived: %s already exists",
+		"Заказ %s не перемещён в архив: на его файлы ссылается %s":          "Order %s not archived: its files are referenced by %s",
+		"Заказ %s не сокращён: %s уже существует":                           "Order %s not summarized: %s already exists",
+		"Заказ %s не сокращён: %v":                                          "Order %s not summarized: %v",
+		"Заказ %s не упакован: %s.zip уже существует":                       "Order %s not compressed: %s.zip already exists",
+		"Заказ %s не упакован: %v":                                          "Order %s not compressed: %v",
+		"Заказ %s не учтён в статистике: %v":                                "Order %s skipped in statistics: %v",
+		"Заказ %s перемещён в %s":                                           "Order %s moved to %s",
+		"Заказ %s упакован в %s":                                            "Order %s compressed to %s",
+		"Заказ из архива %s восстановлен в %s":                              "Order from archive %s restored to %s",
+		"Заказов":  "Orders",
+		"Заказчик": "Customer",
+		"Запуск с аргументами %q":                "Started with arguments %q",
+		"Игнорируемые папки: %v":                 "Ignored folders: %v",
+		"Изменение готовности":                   "Readiness changed",
+		"Изменений нет":                          "No changes",
+		"Изменения с прошлого запуска (%s):\n%s": "Changes since the previous run (%s):\n%s",
+		"Иное": "Other",
+		"Использование: check-names [папка относительно SourceDir]":                             "Usage: check-names [folder relative to SourceDir]",
+		"Использование: cleanup [-dry-run]":                                                     "Usage: cleanup [-dry-run]",
+		"Использование: diff [старый отчёт [новый отчёт]] | diff -current [отчёт]":              "Usage: diff [old report [new report]] | diff -current [report]",
+		"Использование: refresh-lists [папка относительно SourceDir]":                           "Usage: refresh-lists [folder relative to SourceDir]",
+		"Использование: restore [-keep-ready] <zip-файл относительно TargetDir или имя заказа>": "Usage: restore [-keep-ready] <zip file relative to TargetDir or order name>",
+		"Использование: status [-all] [-color | -no-color] [папка относительно SourceDir]":      "Usage: status [-all] [-color | -no-color] [folder relative to SourceDir]",
+		"Материал": "Material",
+		"Месяц":    "Month",
+		"Метка %s: некорректная дата готовности %q":                "Marker %s: invalid ready date %q",
+		"Метка %s не содержит сведений о готовом заказе":           "Marker %s has no ready order information",
+		"Метка %s переведена в формат версии %d":                   "Marker %s converted to format version %d",
+		"Настройки прочитаны из файла:":                            "Settings read from file:",
+		"Настройки успешно загружены из %s.":                       "Settings loaded from %s.",
+		"Начало обработки папки: %s":                               "Processing folder: %s",
+		"Не удалось определить дату готовности заказа %s: %v":      "Cannot determine the ready date of order %s: %v",
+		"Не удалось получить информацию о %s: %v":                  "Cannot stat %s: %v",
+		"Не удалось прочитать папку %s: %v":                        "Cannot read folder %s: %v",
+		"Не удалось прочитать файл настроек %s: %w":                "Cannot read settings file %s: %w",
+		"Не удалось разобрать %s при проверке ссылок на заказы":    "Cannot parse %s while checking order references",
+		"Не удалось разобрать XML из файла настроек %s: %w":        "Cannot parse XML in settings file %s: %w",
+		"Не удалось сверить список заданий %s с файлами папки: %v": "Failed to compare work list %s with folder files: %v",
+		"Не удалось создать файл настроек по умолчанию":            "Cannot create default settings file",
+		"Не удалось сравнить с прошлым запуском: %v":               "Cannot compare with the previous run: %v",
+		"Не удалось удалить опустевшую папку %s: %v":               "Cannot remove empty folder %s: %v",
+		"Некорректная дата готовности %q у заказа %s":              "Invalid ready date %q for order %s",
+		"Новые заказы":                                 "New orders",
+		"Обновлены имена панелей в %s":                 "Panel names updated in %s",
+		"Обработка папки %s":                           "Processing folder %s",
+		"Одинаковый код детали у файлов %s в папке %s": "Same part code in files %s in folder %s",
+		"Ожидает":        "Pending",
+		"Ожидают":        "Pending",
+		"Отчёт о работе": "Work report",
+		"Ошибка в настройке устаревших списков работ в файле настроек %s: %w":    "Error in the stale work list setting in settings file %s: %w",
+		"Ошибка в описании иерархии в файле настроек %s: %w":                     "Invalid hierarchy in settings file %s: %w",
+		"Ошибка в описании станков в файле настроек %s: %w":                      "Invalid machines in settings file %s: %w",
+		"Ошибка в правилах имён файлов в файле настроек %s: %w":                  "Invalid file name rules in settings file %s: %w",
+		"Ошибка в правилах записи путей в list.xml в файле настроек %s: %w":      "Invalid list.xml path rules in settings file %s: %w",
+		"Ошибка в языке сообщений в файле настроек %s: %w":                       "Invalid message language in settings file %s: %w",
+		"Ошибка в правилах хранения в файле настроек %s: %w":                     "Invalid retention rules in settings file %s: %w",
+		"Ошибка в шаблоне пути архива в файле настроек %s: %w":                   "Invalid archive path template in settings file %s: %w",
+		"Ошибка записи метки %s: %v":                                             "Error writing marker %s: %v",
+		"Ошибка записи файла %s: %v":                                             "Error writing file %s: %v",
+		"Ошибка извлечения даты из имени файла %s: %v":                           "Cannot extract date from file name %s: %v",
+		"Ошибка обновления списка заданий %s: %v":                                "Error refreshing work list %s: %v",
+		"Ошибка перемещения директории %s: %v\n\nЗакройте окно Проводника!":      "Error moving folder %s: %v\n\nClose the Explorer window!",
+		"Ошибка при разборе XML-файла %s: %w":                                    "Error parsing XML file %s: %w",
+		"Ошибка при сериализации XML: %v":                                        "Error serializing XML: %v",
+		"Ошибка при сериализации обновленного XML: %v":                           "Error serializing updated XML: %v",
+		"Ошибка раскладки %s: %v":                                                "Layout error %s: %v",
+		"Ошибка создания папки %s: %v":                                           "Error creating folder %s: %v",
+		"Ошибка сохранения статистики в CSV: %v":                                 "Error saving statistics to CSV: %v",
+		"Ошибка сохранения статистики в HTML: %v":                                "Error saving statistics to HTML: %v",
+		"Ошибка удаления %s: %v":                                                 "Error deleting %s: %v",
+		"Ошибка удаления архива %s: %v":                                          "Error deleting archive %s: %v",
+		"Ошибка удаления заказа %s: %v":                                          "Error deleting order %s: %v",
+		"Ошибка удаления отчёта %s: %v":                                          "Error deleting report %s: %v",
+		"Ошибка упаковки заказа %s: %v":                                          "Error compressing order %s: %v",
+		"Ошибка формирования выгрузки отчёта: %v":                                "Error creating report export: %v",
+		"Ошибка чтения XML-файла %s: %v":                                         "Error reading XML file %s: %v",
+		"Ошибка чтения XML-файла %s: %w":                                         "Error reading XML file %s: %w",
+		"Ошибка чтения директории %s: %v":                                        "Error reading folder %s: %v",
+		"Ошибка чтения настроек (%s): %v. Создание файла настроек по умолчанию.": "Error reading settings (%s): %v. Creating default settings file.",
+		"Ошибка: %v": "Error: %v",
+		"Ошибка: Стартовая директория не определена (ни через аргумент, ни в настройках).": "Error: start folder is not set (neither by argument nor in settings).",
+		"Панелей": "Panels",
+		"Папка":   "Folder",
+		"Папка %s всё ещё недоступна":                             "Folder %s is still unavailable",
+		"Папка %s не найдена":                                     "Folder %s not found",
+		"Папка %s не существует: %v":                              "Folder %s does not exist: %v",
+		"Папка %s уже обрабатывается: %s. Ожидание до %s...":      "Folder %s is already being processed: %s. Waiting until %s...",
+		"Перемещены в архив":                                      "Moved to archive",
+		"Площадь, м²":                                             "Area, m²",
+		"Попытка чтения файла настроек: %s":                       "Reading settings file: %s",
+		"Правила имён файлов: %s":                                 "File name rules: %s",
+		"Правила хранения (Retention) в файле настроек не заданы": "Retention rules are not set in the settings file",
+		"Предупреждение: Не удалось извлечь количество деталей из имени файла '%s' (%v). Запись в ProcessList не добавлена.":                          "Warning: cannot extract part count from file name '%s' (%v). ProcessList entry not added.",
+		"Предупреждение: Не удалось преобразовать Длину ('%s'), Ширину ('%s') или Толщину ('%s') в число для панели ID='%s'. Имя не будет обновлено.": "Warning: cannot convert Length ('%s'), Width ('%s') or Thickness ('%s') to a number for panel ID='%s'. Name will not be updated.",
+		"Пробный запуск: изменения не вносятся":               "Dry run: no changes are made",
+		"Проверено списков: %d, обновлено: %d":                "Lists checked: %d, refreshed: %d",
+		"Проверено файлов: %d, не соответствуют правилам: %d": "Files checked: %d, not matching the rules: %d",
+		"Проект":   "Project",
+		"Проектов": "Projects",
+		"Путь: %s. Не найдены папки с фасадами (шаблоны: %v)":            "Path: %s. No facade folders found (patterns: %v)",
+		"Путь: %s. Переместите файл ready_fasady.xml в папки с фасадами": "Path: %s. Move ready_fasady.xml into the facade folders",
+		"Снята устаревшая блокировка: %s":                                "Stale lock removed: %s",
+		"Создан список заданий %s (%d файлов)":                           "Work list %s created (%d files)",
+		"Сокращение заказа %s до метки %s":                               "Summarizing order %s to marker %s",
+		"Список заданий %s не совпадает с файлами папки (%s)":            "Work list %s does not match folder files (%s)",
+		"Список заданий %s обновлён (%s)":                                "Work list %s refreshed (%s)",
+		"Сравнение отчётов:\n  %s\n  %s":                                 "Comparing reports:\n  %s\n  %s",
+		"Средний срок, дней":                                             "Average lead time, days",
+		"Стали готовыми":                                                 "Became ready",
+		"Стартовая папка фактическая: %s":                                "Actual start folder: %s",
+		"Статистика производства":                                        "Production statistics",
+		"Статус": "Status",
+		"Требуется участие пользователя: повреждена метка о выполнении заказа: %v":                                                 "User attention required: order completion marker is damaged: %v",
+		"Требуется участие пользователя: некорректная дата готовности %q у папки %s":                                               "User attention required: invalid ready date %q for folder %s",
+		"Требуется участие пользователя: список заданий %s не совпадает с файлами папки (%s), обновите его командой refresh-lists": "User action required: work list %s does not match folder files (%s), refresh it with the refresh-lists command",
+		"Требуется участие пользователя: статус %s у папки %s":                                                                     "User attention required: status %s for folder %s",
+		"Требуют участия":                        "Need attention",
+		"Требуют участия пользователя":           "Need user attention",
+		"Удаление заказа %s (%d мес.)":           "Deleting order %s (%d months)",
+		"Удалено отчётов о работе старше %s: %d": "Work reports older than %s deleted: %d",
+		"Упаковка заказа %s":                     "Compressing order %s",
+		"Файл %s не обновлён: %v":                "File %s not updated: %v",
+		"Файл %s скопирован в %s как %s":         "File %s copied to %s as %s",
+		"Файл настроек по умолчанию '%s' создан. Пожалуйста, отредактируйте его и перезапустите программу.": "Default settings file '%s' created. Please edit it and restart the program.",
+		"архив %s повреждён: %w": "archive %s is damaged: %w",
+		"архив %s создан, но папку заказа не удалось удалить полностью, остаток - %s: %w\n\nЗакройте окно Проводника и удалите остаток!": "archive %s created, but the order folder could not be removed completely, remainder: %s: %w\n\nClose the Explorer window and delete the remainder!",
+		"в %s не найден архив заказа %s":                            "no archive of order %s found in %s",
+		"в архиве нет файла %s из описи":                            "archive lacks file %s listed in the manifest",
+		"в архиве нет файла %s: %w":                                 "archive lacks file %s: %w",
+		"в замене пути %q -> %q не указано начало пути":             "path rule %q -> %q has an empty prefix",
+		"в записанном файле %d панелей вместо %d":                   "written file has %d panels instead of %d",
+		"в имени %q нет даты в формате %s":                          "name %q has no date in %s format",
+		"в имени %q нет кода детали":                                "name %q has no part code",
+		"в имени %q нет количества деталей":                         "name %q has no part count",
+		"в имени %q нет части %s":                                   "name %q has no %s part",
+		"в папке %s нет метки о выполнении":                         "folder %s has no completion marker",
+		"в папке %s нет метки о выполнении, указанной в отчёте":     "folder %s lacks the completion marker named in the report",
+		"в папке %s нет файлов-заданий для списка %s":               "folder %s has no job files for list %s",
+		"в шаблоне имени файла %q нет группы %s":                    "file name pattern %q has no %s group",
+		"деталей сделано %d из %d":                                  "parts done %d of %d",
+		"добавлены: %s":                                             "added: %s",
+		"заказ %s найден в нескольких архивах, укажите путь:\n  %s": "order %s found in several archives, specify the path:\n  %s",
+		"записанное содержимое не прошло проверку: %w":              "written content failed verification: %w",
+		"изменены: %s": "changed: %s",
+		"компьютер %s, пользователь %s, процесс %s, запущено %s":                        "host %s, user %s, process %s, started %s",
+		"количество деталей %q - не число":                                              "part count %q is not a number",
+		"контрольная сумма записанного файла не совпадает":                              "checksum of the written file does not match",
+		"контрольная сумма файла %s не совпадает, файл изменён или повреждён":           "checksum of file %s does not match, the file was modified or damaged",
+		"метка %q не указана в описи":                                                   "marker %q is not listed in the manifest",
+		"метка %s должна содержать один отчёт, найдено: %d":                             "marker %s must contain one report, found: %d",
+		"метка %s не совпадает с отчётом папки %s":                                      "marker %s does not match the report of folder %s",
+		"не удалось вычислить контрольную сумму файла %s: %w":                           "cannot compute checksum of file %s: %w",
+		"не удалось открыть архив %s: %w":                                               "cannot open archive %s: %w",
+		"не удалось прочитать %s из архива: %w":                                         "cannot read %s from archive: %w",
+		"не удалось прочитать метку %s: %w":                                             "cannot read marker %s: %w",
+		"не удалось прочитать файл отчёта %s: %w":                                       "cannot read report file %s: %w",
+		"не удалось разобрать XML из файла отчёта %s: %w":                               "cannot parse XML in report file %s: %w",
+		"не удалось разобрать опись архива: %w":                                         "cannot parse archive manifest: %w",
+		"не удалось разобрать список %s: %w":                                            "failed to parse list %s: %w",
+		"не удалось распаковать %s: %w":                                                 "cannot extract %s: %w",
+		"не удалось создать архив %s: %w":                                               "cannot create archive %s: %w",
+		"не удалось создать блокировку %s: %w":                                          "cannot create lock %s: %w",
+		"не удалось создать директорию %s: %w":                                          "cannot create folder %s: %w",
+		"не удалось сохранить архив %s: %w":                                             "cannot save archive %s: %w",
+		"не удалось упаковать заказ %s: %w":                                             "cannot compress order %s: %w",
+		"неизвестная группа %q в шаблоне имени файла %q":                                "unknown group %q in file name pattern %q",
+		"неизвестная подстановка %s":                                                    "unknown placeholder %s",
+		"неизвестная часть имени %d":                                                    "unknown name part %d",
+		"неизвестное действие с устаревшими списками %q":                                "unknown stale list action %q",
+		"неизвестный вид уровня иерархии %q":                                            "unknown hierarchy level kind %q",
+		"неизвестный разделитель путей %q":                                              "unknown path separator %q",
+		"неизвестный статус %q у папки %s":                                              "unknown status %q for folder %s",
+		"неизвестный формат списка %q у станка %s":                                      "unknown list format %q for machine %s",
+		"некорректная дата %q в имени файла %s":                                         "invalid date %q in file name %s",
+		"некорректная дата готовности %q у папки %s":                                    "invalid ready date %q for folder %s",
+		"некорректное имя заказа %q в описи":                                            "invalid order name %q in the manifest",
+		"некорректное имя файла списка %q у станка %s":                                  "invalid list file name %q for machine %s",
+		"некорректный путь %q в архиве":                                                 "invalid path %q in the archive",
+		"некорректный путь %q в описи":                                                  "invalid path %q in the manifest",
+		"некорректный формат даты %q":                                                   "invalid date layout %q",
+		"некорректный шаблон %q у станка %s: %w":                                        "invalid pattern %q for machine %s: %w",
+		"некорректный шаблон %q уровня %s: %w":                                          "invalid pattern %q for level %s: %w",
+		"некорректный шаблон имени файла %q: %w":                                        "invalid file name pattern %q: %w",
+		"неподдерживаемая версия описи архива %q":                                       "unsupported archive manifest version %q",
+		"неподдерживаемая версия формата %q в файле %s":                                 "unsupported format version %q in file %s",
+		"отрицательный уровень %d у папки %s":                                           "negative level %d for folder %s",
+		"папка %s заблокирована другой копией программы (%v)":                           "folder %s is locked by another instance (%v)",
+		"папка %s уже обрабатывается: %s.\nЕсли программа не запущена, удалите файл %s": "folder %s is already being processed: %s.\nIf the program is not running, delete %s",
+		"папка %s уже существует":                                                       "folder %s already exists",
+		"пустое имя папки в записи %q":                                                  "empty folder name in entry %q",
+		"пустое количество деталей":                                                     "empty part count",
+		"раскроев готово %d из %d":                                                      "cuts ready %d of %d",
+		"символ %q нельзя записать в кодировке windows-1251":                            "character %q cannot be written in windows-1251",
+		"список файлов пуст":                                                            "file list is empty",
+		"сроки хранения не могут быть отрицательными":                                   "retention periods cannot be negative",
+		"у станка %s тип файлов без кода или расширения":                                "machine %s has a file type without code or extension",
+		"у станка пустое или повторяющееся название %q":                                 "machine name %q is empty or repeated",
+		"удалены: %s": "removed: %s",
+		"уровень иерархии %q указан дважды":                            "hierarchy level %q is specified twice",
+		"файл %s в архиве не совпадает с описью":                       "file %s in the archive does not match the manifest",
+		"файл %s в архиве отсутствует в описи":                         "file %s in the archive is not in the manifest",
+		"файл %s изменён: есть Checksum или Generator, но нет Version": "file %s was modified: it has Checksum or Generator but no Version",
+		"файл блокировки %s повреждён: %w":                             "lock file %s is damaged: %w",
+		"файл отчёта %s: %w":                                           "report file %s: %w",
+		"шаблон %q должен быть относительным":                          "pattern %q must be relative",
+		"шаблон %q должен содержать {order} или {path}":                "pattern %q must contain {order} or {path}",
+		"шаблон %q не должен выходить за пределы TargetDir":            "pattern %q must stay inside TargetDir",
+	},
+}
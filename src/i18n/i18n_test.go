@@ -0,0 +1,26 @@
+package i18n
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// Глаголы формата fmt: %v, %s, %d, %.6f, %q, %w, %%
+var formatVerb = regexp.MustCompile(`%[-+# 0]*(?:\[\d+\])?(?:\d+|\*)?(?:\.(?:\d+|\*))?[a-zA-Z%]`)
+
+// Перевод должен содержать те же глаголы формата в том же порядке, что и исходное сообщение:
+// иначе fmt подставит аргументы не туда или выведет %!v(MISSING)
+func TestCatalogFormatVerbs(t *testing.T) {
+	for lang, catalog := range messageCatalogs {
+		for message, translated := range catalog {
+			// Action
+			want := formatVerb.FindAllString(message, -1)
+			got := formatVerb.FindAllString(translated, -1)
+			// Assert
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q -> %q: глаголы %q; want %q", lang, message, translated, got, want)
+			}
+		}
+	}
+}
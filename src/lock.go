@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+)
+
+// Имя файла блокировки в SourceDir
+const lockFileName = "ListMaker.lock"
+
+// XLockHolder: Содержимое файла блокировки - кто и когда запустил программу
+type XLockHolder struct {
+	XMLName xml.Name `xml:"Lock"`
+	Host    string   `xml:"Host,attr"`
+	User    string   `xml:"User,attr"`
+	PID     int      `xml:"PID,attr"`
+	Started string   `xml:"Started,attr"`
+}
+
+// RunLock: Полученная блокировка
+type RunLock struct {
+	path   string
+	holder XLockHolder
+}
+
+/**
+ * acquireLock: Создаёт файл блокировки в папке, чтобы две копии программы не обрабатывали её одновременно.
+ * Устаревшая блокировка (процесс на этом компьютере завершён или прошло больше StaleHours) снимается.
+ * Если блокировка занята, ожидает её освобождения не дольше WaitSeconds.
+ * @param dirPath - Папка для файла блокировки (SourceDir).
+ * @param settings - Настройки программы (время ожидания и устаревания).
+ * @return *RunLock - Блокировка, которую нужно освободить методом release.
+ * @return error - Описание, кем занята блокировка, или ошибка создания файла.
+ */
+func acquireLock(dirPath string, settings config.Settings) (*RunLock, error) {
+	hostName, _ := os.Hostname()
+	lock := &RunLock{
+		path: filepath.Join(dirPath, lockFileName),
+		holder: XLockHolder{
+			Host:    hostName,
+			User:    currentUserName(),
+			PID:     os.Getpid(),
+			Started: time.Now().Format(time.DateTime),
+		},
+	}
+	data, err := xml.Marshal(lock.holder)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(time.Duration(settings.LockWait) * time.Second)
+	waiting := false
+	for {
+		err := fileio.CreateExclusive(lock.path, data, 0666)
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf(i18n.Tr("не удалось создать блокировку %s: %w"), lock.path, err)
+		}
+
+		holder, errRead := readLockHolder(lock.path)
+		if errRead == nil && holder.isStale(hostName, settings.LockStale) {
+			if removeStaleLock(lock.path, holder) {
+				logging.Warn(fmt.Sprintf(i18n.Tr("Снята устаревшая блокировка: %s"), holder), logging.FieldPath, lock.path, logging.FieldAction, "lock-stale")
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			if errRead != nil {
+				return nil, fmt.Errorf(i18n.Tr("папка %s заблокирована другой копией программы (%v)"), dirPath, errRead)
+			}
+			return nil, fmt.Errorf(i18n.Tr("папка %s уже обрабатывается: %s.\nЕсли программа не запущена, удалите файл %s"), dirPath, holder, lock.path)
+		}
+		if !waiting {
+			fmt.Printf(i18n.Tr("Папка %s уже обрабатывается: %s. Ожидание до %s...\n"), dirPath, holder, deadline.Format(time.TimeOnly))
+			waiting = true
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+/**
+ * release: Удаляет файл блокировки, если он по-прежнему принадлежит этому запуску.
+ */
+func (lock *RunLock) release() {
+	if lock == nil {
+		return
+	}
+	if holder, err := readLockHolder(lock.path); err == nil && holder.sameAs(lock.holder) {
+		fileio.Remove(lock.path)
+	}
+}
+
+/**
+ * removeStaleLock: Удаляет устаревшую блокировку, только если файл по-прежнему принадлежит тому же владельцу.
+ * Две ожидающие копии могут одновременно признать блокировку устаревшей; пока вторая проверяла,
+ * первая могла уже снять её и занять папку - чужую свежую блокировку удалять нельзя.
+ * @param lockPath - Путь к файлу блокировки.
+ * @param stale - Владелец, блокировка которого признана устаревшей.
+ * @return bool - true, если файл удалён.
+ */
+func removeStaleLock(lockPath string, stale XLockHolder) bool {
+	holder, err := readLockHolder(lockPath)
+	if err != nil || !holder.sameAs(stale) {
+		return false
+	}
+	return fileio.Remove(lockPath) == nil
+}
+
+/**
+ * waitForEnter: Освобождает блокировку и ждёт нажатия Enter перед закрытием окна.
+ * Окно часто оставляют открытым, а папка не должна оставаться занятой, когда программа уже ничего не делает.
+ */
+func waitForEnter(lock *RunLock) {
+	lock.release()
+	fmt.Println(i18n.Tr("\nДля закрытия окна нажмите Enter"))
+	fmt.Scanln()
+}
+
+/**
+ * releaseOnInterrupt: Освобождает блокировку при прерывании программы (Ctrl+C, закрытие окна).
+ */
+func (lock *RunLock) releaseOnInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		lock.release()
+		os.Exit(1)
+	}()
+}
+
+// Читает файл блокировки
+func readLockHolder(lockPath string) (XLockHolder, error) {
+	var holder XLockHolder
+	data, err := fileio.ReadFile(lockPath)
+	if err != nil {
+		return holder, err
+	}
+	if err := xml.Unmarshal(data, &holder); err != nil {
+		return holder, fmt.Errorf(i18n.Tr("файл блокировки %s повреждён: %w"), lockPath, err)
+	}
+	return holder, nil
+}
+
+/**
+ * isStale: Проверяет, что блокировка устарела: процесс, создавший её на этом компьютере, завершён,
+ * или с её создания прошло больше staleHours часов.
+ */
+func (holder XLockHolder) isStale(hostName string, staleHours int) bool {
+	if holder.Host == hostName && !processExists(holder.PID) {
+		return true
+	}
+	started, err := time.ParseInLocation(time.DateTime, holder.Started, time.Local)
+	return err != nil || time.Since(started) > time.Duration(staleHours)*time.Hour
+}
+
+// Проверяет, что блокировка создана тем же запуском программы
+func (holder XLockHolder) sameAs(other XLockHolder) bool {
+	return holder.Host == other.Host && holder.PID == other.PID && holder.Started == other.Started
+}
+
+// Описание владельца блокировки для сообщений
+func (holder XLockHolder) String() string {
+	return fmt.Sprintf(i18n.Tr("компьютер %s, пользователь %s, процесс %s, запущено %s"),
+		holder.Host, holder.User, strconv.Itoa(holder.PID), holder.Started)
+}
+
+// Проверяет, существует ли процесс с указанным номером на этом компьютере
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// в Windows FindProcess завершается ошибкой для несуществующего процесса
+	if runtime.GOOS == "windows" {
+		process.Release()
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Имя пользователя, запустившего программу
+func currentUserName() string {
+	for _, name := range []string{"USERNAME", "USER"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+// Пакет logging - журнал ListMaker.log с уровнями сообщений и ротацией.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// уровни сообщений журнала
+const (
+	c_LOG_DEBUG = iota // подробности: каждая папка и каждый файл
+	c_LOG_INFO         // основные действия: созданные списки, метки, перемещения
+	c_LOG_WARN         // требуется участие пользователя
+	c_LOG_ERROR        // ошибки
+)
+
+var logLevelNames = map[int]string{
+	c_LOG_DEBUG: "DEBUG",
+	c_LOG_INFO:  "INFO",
+	c_LOG_WARN:  "WARN",
+	c_LOG_ERROR: "ERROR",
+}
+
+// ключи полей сообщений журнала
+const (
+	FieldPath   = "path"   // папка или файл, к которым относится сообщение
+	FieldAction = "action" // выполненное действие
+	FieldError  = "error"  // текст ошибки
+)
+
+// Имя файла журнала в TargetDir
+const FileName = "ListMaker.log"
+
+// Размер файла журнала, после которого он заменяется новым, и число хранимых старых файлов
+const (
+	DefaultMaxSizeKB = 1024
+	DefaultKeep      = 5
+)
+
+// Logger: Журнал работы - сообщения выводятся в консоль и пишутся в файл с датой, уровнем и полями
+// Файл журнала открывается и сдвигается через os, а не через fileio.FileSystem: он открыт всё время работы
+// и дописывается по строке, а FileSystem читает и записывает файлы только целиком
+type Logger struct {
+	mu           sync.Mutex
+	consoleLevel int      // Минимальный уровень сообщений в консоли
+	fileLevel    int      // Минимальный уровень сообщений в файле
+	file         *os.File // Файл журнала, nil - пока не открыт
+	filePath     string
+	size         int64
+	maxSize      int64
+	keep         int
+}
+
+// Журнал программы; до открытия файла сообщения выводятся только в консоль
+var appLog = &Logger{consoleLevel: c_LOG_INFO, fileLevel: c_LOG_INFO}
+
+/**
+ * ParseFlags: Убирает из аргументов командной строки ключи --verbose и --quiet и применяет их к журналу.
+ * --verbose - в консоли и файле выводятся подробности (DEBUG), --quiet - в консоли только предупреждения и ошибки.
+ * @param args - Аргументы командной строки без имени программы.
+ * @return []string - Остальные аргументы.
+ */
+func ParseFlags(args []string) []string {
+	var rest []string
+	for _, arg := range args {
+		switch arg {
+		case "--verbose", "-v":
+			appLog.consoleLevel, appLog.fileLevel = c_LOG_DEBUG, c_LOG_DEBUG
+		case "--quiet", "-q":
+			appLog.consoleLevel = c_LOG_WARN
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest
+}
+
+/**
+ * OpenFile: Открывает файл журнала ListMaker.log в папке и направляет в журнал сообщения пакета log.
+ * Если файл больше MaxSizeKB, он переименовывается в ListMaker.log.1 (старые сдвигаются, лишние удаляются).
+ * @param dirPath - Папка для журнала (TargetDir).
+ * @param maxSizeKB - Размер файла журнала, после которого начинается новый.
+ * @param keep - Сколько старых файлов журнала хранить.
+ * @return error - Ошибка открытия файла; сообщения при этом выводятся только в консоль.
+ */
+func OpenFile(dirPath string, maxSizeKB int, keep int) error {
+	OpenConsole()
+	appLog.mu.Lock()
+	defer appLog.mu.Unlock()
+	if err := os.MkdirAll(dirPath, 0777); err != nil {
+		return err
+	}
+	appLog.filePath = filepath.Join(dirPath, FileName)
+	appLog.maxSize = int64(maxSizeKB) * 1024
+	appLog.keep = keep
+	if info, err := os.Stat(appLog.filePath); err == nil && info.Size() >= appLog.maxSize {
+		appLog.rotate()
+	}
+	return appLog.open()
+}
+
+/**
+ * OpenConsole: Направляет сообщения пакета log в журнал, не открывая файл: сообщения выводятся только в консоль.
+ * Для команд, только читающих файлы (status, diff, check-names): их запуск не должен сдвигать файлы журнала.
+ */
+func OpenConsole() {
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{})
+}
+
+/**
+ * CloseFile: Закрывает файл журнала.
+ */
+func CloseFile() {
+	appLog.mu.Lock()
+	defer appLog.mu.Unlock()
+	if appLog.file != nil {
+		appLog.file.Close()
+		appLog.file = nil
+	}
+}
+
+// Открывает файл журнала для дописывания
+func (l *Logger) open() error {
+	file, err := os.OpenFile(l.filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// Сдвигает старые файлы журнала: ListMaker.log -> ListMaker.log.1 -> ... -> ListMaker.log.<keep>
+func (l *Logger) rotate() {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	os.Remove(l.filePath + "." + strconv.Itoa(l.keep))
+	for i := l.keep - 1; i >= 1; i-- {
+		os.Rename(l.filePath+"."+strconv.Itoa(i), l.filePath+"."+strconv.Itoa(i+1))
+	}
+	if l.keep > 0 {
+		os.Rename(l.filePath, l.filePath+".1")
+	} else {
+		os.Remove(l.filePath)
+	}
+}
+
+/**
+ * write: Выводит сообщение в консоль и записывает его в файл журнала.
+ * Строка файла: дата и время, уровень, сообщение и поля вида ключ="значение".
+ * @param level - Уровень сообщения (c_LOG_*).
+ * @param message - Текст сообщения.
+ * @param fields - Пары ключ, значение (Field* или другие).
+ */
+func (l *Logger) write(level int, message string, fields ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level >= l.consoleLevel {
+		fmt.Println(message)
+	}
+	if l.file == nil || level < l.fileLevel {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(time.Now().Format("2006-01-02 15:04:05.000"))
+	fmt.Fprintf(&sb, " %-5s %s", logLevelNames[level], strconv.Quote(strings.TrimSpace(message)))
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&sb, " %v=%s", fields[i], strconv.Quote(fmt.Sprint(fields[i+1])))
+	}
+	sb.WriteString("\n")
+	if l.maxSize > 0 && l.size+int64(sb.Len()) > l.maxSize {
+		l.rotate()
+		if l.open() != nil {
+			return
+		}
+	}
+	n, _ := l.file.WriteString(sb.String())
+	l.size += int64(n)
+}
+
+// Сообщения журнала по уровням: текст и пары ключ, значение
+func Debug(message string, fields ...any) { appLog.write(c_LOG_DEBUG, message, fields...) }
+
+func Info(message string, fields ...any) { appLog.write(c_LOG_INFO, message, fields...) }
+
+func Warn(message string, fields ...any) { appLog.write(c_LOG_WARN, message, fields...) }
+
+func Error(message string, fields ...any) { appLog.write(c_LOG_ERROR, message, fields...) }
+
+// stdLogWriter: Направляет сообщения пакета log (log.Printf) в журнал с уровнем WARN
+type stdLogWriter struct{}
+
+func (stdLogWriter) Write(p []byte) (int, error) {
+	appLog.write(c_LOG_WARN, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
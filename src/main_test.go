@@ -0,0 +1,128 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+	"github.com/ProOwler/ListMaker/fixture/fixturetest"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// Строит в памяти папку /work с настройками (extra - дополнительные элементы файла настроек) и пустой TargetDir,
+// возвращает дерево SourceDir и настройки
+func newTestTree(t *testing.T, extra ...string) (fixture.Tree, config.Settings) {
+	t.Helper()
+	fixturetest.Mem(t)
+	work := fixture.New("/work")
+	var settings config.Settings
+	if err := settings.ReadFromFile(work.Settings("listMaker_settings.xml", "./src", "./done", extra...)); err != nil {
+		t.Fatal(err)
+	}
+	fixture.New(settings.DirTarget)
+	return fixture.New(settings.DirSource), settings
+}
+
+// Возвращает имена файлов папки, оканчивающиеся на suffix
+func filesWithSuffix(t *testing.T, dirPath string, suffix string) []string {
+	t.Helper()
+	entries, err := fileio.ReadDir(dirPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), suffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// Полный запуск на дереве в памяти: list.xml, метки, перемещение в архив, отчёты и статистика
+func TestProcessSourceDirectory(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Иванов/Кухня/ЛДСП Белый/1.2_3_Дверь.xml", 700, 400, 3)
+	tree.Ready("Иванов/Кухня/ЛДСП Белый", "20250601")
+	tree.Panel("Иванов/Кухня/ЛДСП Серый/2_1_Полка.xml", 500, 400, 1)
+	tree.Ready("Иванов/Кухня/ЛДСП Серый", "20250603")
+	tree.Panel("Петров/Шкаф/ЛДСП Дуб/1_2_Бок.xml", 2000, 400, 2)
+	tree.MPR("Петров/Шкаф/ЛДСП Дуб/3_1_Фреза.mpr")
+	// Action
+	processSourceDirectory(settings.DirSource, settings)
+	// Assert
+	marker := filepath.Join(settings.DirTarget, "2025-06", "Иванов", "order_ready_20250603.xml")
+	if _, err := fileio.Stat(marker); err != nil {
+		t.Errorf("готовый заказ не перемещён в архив: %v", err)
+	}
+	if _, err := fileio.Stat(tree.Path("Иванов")); err == nil {
+		t.Errorf("готовый заказ остался в SourceDir")
+	}
+	list, err := fileio.ReadFile(tree.Path("Петров/Шкаф/ЛДСП Дуб/list.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixturetest.Golden(t, "process_list.xml", list)
+	for _, suffix := range []string{"_WorkReport.txt", "_WorkReport.xml", "_WorkReport.html", "_WorkReport.csv", "Statistics.csv", "Statistics.html"} {
+		if names := filesWithSuffix(t, settings.DirTarget, suffix); len(names) != 1 {
+			t.Errorf("в TargetDir файлов *%s: %q; want 1", suffix, names)
+		}
+	}
+	reportText, _ := fileio.ReadFile(filepath.Join(settings.DirTarget, filesWithSuffix(t, settings.DirTarget, "_WorkReport.txt")[0]))
+	fixturetest.Golden(t, "process_report.txt", reportText)
+}
+
+// Заказ не перемещается в архив, если на его файлы ссылается list.xml с путями, записанными для станка
+func TestProcessMappedListReference(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t, `<ListPaths Separator="windows"><Map From="/work/src" To="\\server\Заказы"/></ListPaths>`)
+	tree.Panel("Иванов/Кухня/ЛДСП Белый/1.2_3_Дверь.xml", 700, 400, 3)
+	tree.Ready("Иванов/Кухня/ЛДСП Белый", "20250601")
+	tree.Panel("Петров/Шкаф/ЛДСП Дуб/1_2_Бок.xml", 2000, 400, 2)
+	// list.xml в папке Петрова ссылается и на деталь Иванова
+	listData := worklist.GetOutputXML([]string{tree.Path("Петров/Шкаф/ЛДСП Дуб/1_2_Бок.xml"), tree.Path("Иванов/Кухня/ЛДСП Белый/1.2_3_Дверь.xml")},
+		worklist.DefaultFileFormats, settings.ListPaths, settings.NameGrammar)
+	if err := fileio.WriteFile(tree.Path("Петров/Шкаф/ЛДСП Дуб/list.xml"), []byte(listData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	processSourceDirectory(settings.DirSource, settings)
+	// Assert
+	if !strings.Contains(listData, `<FilePath>\\server\Заказы\Иванов\Кухня\ЛДСП Белый\1.2_3_Дверь.xml</FilePath>`) {
+		t.Errorf("путь записан без замены:\n%s", listData)
+	}
+	if _, err := fileio.Stat(tree.Path("Иванов")); err != nil {
+		t.Errorf("заказ, на файлы которого ссылается list.xml, перемещён в архив: %v", err)
+	}
+}
+
+// Занятая работающим процессом папка не блокируется второй раз, освобождённая - снова доступна
+func TestAcquireLockBusy(t *testing.T) {
+	// Arrange
+	fixturetest.Mem(t)
+	tree := fixture.New("/src")
+	settings := config.Settings{LockWait: 0, LockStale: 12}
+	lock, err := acquireLock(tree.Root, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	_, errBusy := acquireLock(tree.Root, settings)
+	lock.release()
+	again, errAgain := acquireLock(tree.Root, settings)
+	// Assert
+	if errBusy == nil {
+		t.Error("папка заблокирована второй раз")
+	}
+	if errAgain != nil {
+		t.Errorf("после освобождения: %v", errAgain)
+	}
+	again.release()
+	if _, err := fileio.Stat(tree.Path(lockFileName)); err == nil {
+		t.Error("файл блокировки не удалён")
+	}
+}
@@ -0,0 +1,185 @@
+package panel
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// именованные группы в шаблонах имён файлов
+const (
+	GroupID       = "id"       // код детали, по нему сортируется список заданий
+	GroupQty      = "qty"      // количество деталей
+	GroupMaterial = "material" // материал или название детали
+	GroupDate     = "date"     // дата готовности
+	GroupSuffix   = "suffix"   // остаток имени
+)
+
+// Шаблоны по умолчанию: <код>_<количество>_<название>... и ready_yyyymmdd, order_ready_yyyymmdd
+const (
+	DefaultNamePattern  = `^(?P<id>[^_]+)(?:_(?P<qty>[^_]*))?(?:_(?P<material>[^_]*))?(?:_(?P<suffix>.*))?$`
+	DefaultReadyPattern = `(?:^|_)(?P<date>\d{8})$`
+	DefaultDateLayout   = "20060102"
+)
+
+// NameGrammar: Правила разбора имён файлов (без расширения) на части именованными группами регулярных выражений
+type NameGrammar struct {
+	name       *regexp.Regexp // имена файлов-заданий: группы id, qty и, по желанию, material, date, suffix
+	ready      *regexp.Regexp // имена выполненных плейлистов и меток: группа date
+	dateLayout string         // формат даты в группе date (как в time.Parse)
+}
+
+// правила разбора имён по умолчанию
+var defaultGrammar = mustNameGrammar(DefaultNamePattern, DefaultReadyPattern, DefaultDateLayout)
+
+/**
+ * NewNameGrammar: Проверяет и компилирует правила разбора имён файлов.
+ * @param namePattern - Шаблон имени файла-задания; обязательны группы id и qty.
+ * @param readyPattern - Шаблон имени выполненного плейлиста; обязательна группа date.
+ * @param dateLayout - Формат даты в группе date, например 20060102.
+ * @return *NameGrammar - Правила разбора.
+ * @return error - Ошибка, если шаблон некорректен, в нём нет обязательной группы
+ * или есть группа с неизвестным именем, либо формат даты не задаёт год, месяц и день.
+ */
+func NewNameGrammar(namePattern string, readyPattern string, dateLayout string) (*NameGrammar, error) {
+	name, err := compileNamePattern(namePattern, GroupID, GroupQty)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := compileNamePattern(readyPattern, GroupDate)
+	if err != nil {
+		return nil, err
+	}
+	// формат должен однозначно восстанавливать дату
+	sample := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	if parsed, err := time.Parse(dateLayout, sample.Format(dateLayout)); err != nil || !parsed.Equal(sample) {
+		return nil, fmt.Errorf(i18n.Tr("некорректный формат даты %q"), dateLayout)
+	}
+	return &NameGrammar{name: name, ready: ready, dateLayout: dateLayout}, nil
+}
+
+// Компилирует шаблон и проверяет имена его групп
+func compileNamePattern(pattern string, required ...string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.Tr("некорректный шаблон имени файла %q: %w"), pattern, err)
+	}
+	for _, group := range re.SubexpNames() {
+		switch group {
+		case "", GroupID, GroupQty, GroupMaterial, GroupDate, GroupSuffix:
+		default:
+			return nil, fmt.Errorf(i18n.Tr("неизвестная группа %q в шаблоне имени файла %q"), group, pattern)
+		}
+	}
+	for _, group := range required {
+		if re.SubexpIndex(group) < 0 {
+			return nil, fmt.Errorf(i18n.Tr("в шаблоне имени файла %q нет группы %s"), pattern, group)
+		}
+	}
+	return re, nil
+}
+
+// Правила по умолчанию проверены при разработке, ошибка в них - ошибка программы
+func mustNameGrammar(namePattern string, readyPattern string, dateLayout string) *NameGrammar {
+	grammar, err := NewNameGrammar(namePattern, readyPattern, dateLayout)
+	if err != nil {
+		panic(err)
+	}
+	return grammar
+}
+
+/**
+ * DefaultNameGrammar: Возвращает правила разбора имён по умолчанию (ID_КОЛИЧЕСТВО_..., ready_yyyymmdd).
+ */
+func DefaultNameGrammar() *NameGrammar {
+	return defaultGrammar
+}
+
+// Правила разбора, nil - правила по умолчанию (например, у настроек, не прочитанных из файла)
+func (grammar *NameGrammar) orDefault() *NameGrammar {
+	if grammar == nil {
+		return defaultGrammar
+	}
+	return grammar
+}
+
+/**
+ * String: Описание правил для журнала и сообщений.
+ */
+func (grammar *NameGrammar) String() string {
+	grammar = grammar.orDefault()
+	return fmt.Sprintf("%s | %s | %s", grammar.name, grammar.ready, grammar.dateLayout)
+}
+
+/**
+ * part: Возвращает группу из имени файла-задания или даты готовности.
+ * @param filename - Имя файла без расширения.
+ * @param group - Имя группы (GroupID, GroupQty, ...).
+ * @return string - Значение группы, пустое - если группа совпала с пустой строкой.
+ * @return bool - false, если имя не подходит к шаблону или группа в нём не участвовала.
+ */
+func (grammar *NameGrammar) part(filename string, group string) (string, bool) {
+	re := grammar.name
+	if group == GroupDate {
+		re = grammar.ready
+	}
+	index := re.SubexpIndex(group)
+	if index < 0 {
+		return "", false
+	}
+	match := re.FindStringSubmatchIndex(filename)
+	if match == nil || match[2*index] < 0 {
+		return "", false
+	}
+	return filename[match[2*index]:match[2*index+1]], true
+}
+
+/**
+ * readyDate: Извлекает и проверяет дату готовности из имени файла без расширения.
+ * @return time.Time - Дата готовности.
+ * @return error - Ошибка, если даты в имени нет или она некорректна.
+ */
+func (grammar *NameGrammar) readyDate(filename string) (time.Time, error) {
+	datePart, ok := grammar.part(filename, GroupDate)
+	if !ok {
+		return time.Time{}, fmt.Errorf(i18n.Tr("в имени %q нет даты в формате %s"), filename, grammar.dateLayout)
+	}
+	date, err := time.Parse(grammar.dateLayout, datePart)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(i18n.Tr("некорректная дата %q в имени файла %s"), datePart, filename)
+	}
+	return date, nil
+}
+
+/**
+ * CheckFileName: Проверяет имя файла по правилам разбора (команда check-names).
+ * Файлы с ready в имени (кроме ready_fasady) проверяются на дату готовности, файлы-задания - на код детали
+ * и количество; остальные файлы не проверяются. Файлы-задания определяет профиль станка папки
+ * (MachineProfile.IsJobFile), как при обходе.
+ * @param shortFileName - Имя файла без пути.
+ * @param isJobFile - Файл - задание для станка папки.
+ * @return bool - true, если файл проверялся.
+ * @return error - Причина, по которой имя не подходит к правилам.
+ */
+func (grammar *NameGrammar) CheckFileName(shortFileName string, isJobFile bool) (bool, error) {
+	if strings.Contains(shortFileName, "ready") {
+		if strings.Contains(shortFileName, "fasady") {
+			return false, nil
+		}
+		_, err := grammar.GetReadyDate(shortFileName)
+		return true, err
+	}
+	if !isJobFile {
+		return false, nil
+	}
+	baseName := strings.TrimSuffix(shortFileName, filepath.Ext(shortFileName))
+	if _, err := grammar.GetPartFromDividedString(baseName, PartID); err != nil {
+		return true, err
+	}
+	_, err := grammar.CountDetails(baseName)
+	return true, err
+}
@@ -0,0 +1,165 @@
+package panel
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// стоп-слова, наличие которых надо проверять в именах файлов
+var stopWords = []string{"fasady", "list", "ready"}
+
+// части имени файла для GetPartFromDividedString
+const (
+	PartDetail   int = 2
+	PartMaterial int = 3
+	PartSuffix   int = 4
+	PartDate     int = -1
+	PartID       int = 10
+)
+
+/**
+ * GetReadyDate: Извлекает дату готовности из имени файла вида ready_yyyymmdd.xml или order_ready_yyyymmdd.xml
+ * по правилам по умолчанию (см. NameGrammar.GetReadyDate).
+ */
+func GetReadyDate(shortFileName string) (string, error) {
+	return defaultGrammar.GetReadyDate(shortFileName)
+}
+
+/**
+ * GetReadyDate: Извлекает дату готовности из имени файла вида ready_yyyymmdd.xml или order_ready_yyyymmdd.xml
+ * (по шаблону ReadyPattern из настроек). Метки order_ready_yyyymmdd.xml пишет сама программа,
+ * поэтому имя, не подошедшее к шаблону из настроек, проверяется и по шаблону по умолчанию.
+ * @param shortFileName - Имя файла без пути.
+ * @return string - Дата в формате yyyy-mm-dd.
+ * @return error - Ошибка, если в имени нет корректной даты.
+ */
+func (grammar *NameGrammar) GetReadyDate(shortFileName string) (string, error) {
+	grammar = grammar.orDefault()
+	baseName := strings.TrimSuffix(shortFileName, filepath.Ext(shortFileName))
+	date, err := grammar.readyDate(baseName)
+	if err != nil && grammar != defaultGrammar {
+		if defaultDate, errDefault := defaultGrammar.readyDate(baseName); errDefault == nil {
+			date, err = defaultDate, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return date.Format(time.DateOnly), nil
+}
+
+/**
+ * HasStopWord: Проверяет наличие стоп-слов в строке (без учета регистра).
+ * @param examinedStr - Проверяемая строка.
+ * @return bool - true, если стоп-слово найдено, иначе false.
+ */
+func HasStopWord(examinedStr string) bool {
+	for _, item := range stopWords {
+		if strings.Contains(strings.ToLower(examinedStr), strings.ToLower(item)) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * CountDetails: Извлекает количество деталей из кода детали по правилам по умолчанию (см. NameGrammar.CountDetails).
+ */
+func CountDetails(detailCode string) (string, error) {
+	return defaultGrammar.CountDetails(detailCode)
+}
+
+/**
+ * CountDetails: Извлекает количество деталей из строки (кода детали).
+ * Ожидает формат типа "КОД_КОЛИЧЕСТВО_..." (или заданный в настройках шаблон)
+ * @param detailCode - Строка с кодом детали (обычно имя файла без расширения).
+ * @return string - Строка с количеством.
+ * @return error - Ошибка, если количества нет или формат неверный.
+ */
+func (grammar *NameGrammar) CountDetails(detailCode string) (string, error) {
+	// Ожидаем как минимум 2 части (код_количество)
+	amount, err := grammar.GetPartFromDividedString(detailCode, PartDetail)
+	if err != nil {
+		return "", err
+	}
+	if err := checkDetailsAmount(amount); err != nil {
+		return "", err
+	}
+	// Если все проверки пройдены, возвращаем извлеченное количество
+	return amount, nil
+}
+
+/**
+ * GetPartFromDividedString: Возвращает часть имени файла по правилам по умолчанию
+ * (см. NameGrammar.GetPartFromDividedString).
+ */
+func GetPartFromDividedString(filename string, flag int) (string, error) {
+	return defaultGrammar.GetPartFromDividedString(filename, flag)
+}
+
+/**
+ * GetPartFromDividedString: Возвращает часть имени файла по правилам разбора из настроек
+ * (по умолчанию имя делится на части символом "_").
+ * @param filename - Имя файла без расширения.
+ * @param flag - Какая часть нужна: PartID (код детали), PartDetail (количество), PartMaterial,
+ * PartSuffix или PartDate (дата готовности, по умолчанию последняя часть из 8 цифр).
+ * @return string - Найденная часть.
+ * @return error - Ошибка, если нужной части нет.
+ */
+func (grammar *NameGrammar) GetPartFromDividedString(filename string, flag int) (string, error) {
+	grammar = grammar.orDefault()
+	switch flag {
+	case PartDetail:
+		if amount, ok := grammar.part(filename, GroupQty); ok {
+			return amount, nil
+		}
+		return "", fmt.Errorf(i18n.Tr("в имени %q нет количества деталей"), filename)
+	case PartDate:
+		if date, ok := grammar.part(filename, GroupDate); ok && date != "" {
+			return date, nil
+		}
+		return "", fmt.Errorf(i18n.Tr("в имени %q нет даты в формате %s"), filename, grammar.dateLayout)
+	case PartID:
+		if id, ok := grammar.part(filename, GroupID); ok && id != "" {
+			return id, nil
+		}
+		return "", fmt.Errorf(i18n.Tr("в имени %q нет кода детали"), filename)
+	case PartMaterial, PartSuffix:
+		group := GroupMaterial
+		if flag == PartSuffix {
+			group = GroupSuffix
+		}
+		if value, ok := grammar.part(filename, group); ok {
+			return value, nil
+		}
+		return "", fmt.Errorf(i18n.Tr("в имени %q нет части %s"), filename, group)
+	default:
+		return "", fmt.Errorf(i18n.Tr("неизвестная часть имени %d"), flag)
+	}
+}
+
+/**
+ * checkDetailsAmount: Проверяет строковое значение количества деталей.
+ * @param inString - Строка с предположительно количеством деталей
+ * @return error - Ошибка, если это не целое неотрицательное число
+ */
+func checkDetailsAmount(inString string) error {
+	if inString == "" {
+		return fmt.Errorf(i18n.Tr("пустое количество деталей"))
+	}
+	// Проверяем, что строка состоит только из цифр 0-9 и помещается в int
+	for _, r := range inString {
+		if r < '0' || r > '9' {
+			return fmt.Errorf(i18n.Tr("количество деталей %q - не число"), inString)
+		}
+	}
+	if _, err := strconv.Atoi(inString); err != nil {
+		return fmt.Errorf(i18n.Tr("количество деталей %q - не число"), inString)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package panel
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestGetReadyDate(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"ready_20250601.xml", "2025-06-01", false},
+		{"order_ready_20241231.xml", "2024-12-31", false},
+		{"ready_20250631.xml", "", true}, // 31 июня
+		{"ready_2025-6-1.xml", "", true},
+		{"ready_июнь.xml", "", true}, // 8 байт, но не дата
+		{"ready_fasady.xml", "", true},
+		{"ready.xml", "", true},
+		{"", "", true},
+	}
+	for _, test := range tests {
+		// Action
+		got, err := GetReadyDate(test.name)
+		// Assert
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("GetReadyDate(%q) = %q, %v; want %q, error %t", test.name, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestCountDetails(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		code    string
+		want    string
+		wantErr bool
+	}{
+		{"1.2_3_Дверь", "3", false},
+		{"/src/Заказ/10_12", "12", false},
+		{"1_Бок", "", true},
+		{"1", "", true},
+		{"1__Бок", "", true},
+		{"1_-3_Бок", "", true},
+		{"1_٣_Бок", "", true}, // арабская цифра
+		{"1_99999999999999999999999_Бок", "", true},
+	}
+	for _, test := range tests {
+		// Action
+		got, err := CountDetails(test.code)
+		// Assert
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("CountDetails(%q) = %q, %v; want %q, error %t", test.code, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestGetPartFromDividedString(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		name    string
+		flag    int
+		want    string
+		wantErr bool
+	}{
+		{"1.2_3_Дверь", PartID, "1.2", false},
+		{"1.2_3_Дверь", PartDetail, "3", false},
+		{"order_ready_20250601", PartDate, "20250601", false},
+		{"_3_Дверь", PartID, "", true},
+		{"Дверь", PartDetail, "", true},
+		{"ready_2025", PartDate, "", true},
+		{"1_2_3", 0, "", true},
+	}
+	for _, test := range tests {
+		// Action
+		got, err := GetPartFromDividedString(test.name, test.flag)
+		// Assert
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("GetPartFromDividedString(%q, %d) = %q, %v; want %q, error %t", test.name, test.flag, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+// Имена файлов для начального корпуса fuzz-тестов
+var fuzzNames = []string{
+	"ready_20250601.xml", "order_ready_20250603.xml", "ready_fasady.xml", "1.2_3_Дверь.xml",
+	"10_1_Крыша", "_", "__", "", "ready_июнь.xml", "ready_\xff\xfe\xfd\xfc\xfb\xfa\xf9\xf8.xml",
+}
+
+func FuzzGetReadyDate(f *testing.F) {
+	for _, name := range fuzzNames {
+		f.Add(name)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		got, err := GetReadyDate(name)
+		if err != nil {
+			if got != "" {
+				t.Errorf("GetReadyDate(%q) = %q вместе с ошибкой %v", name, got, err)
+			}
+			return
+		}
+		if _, errParse := time.Parse(time.DateOnly, got); errParse != nil {
+			t.Errorf("GetReadyDate(%q) = %q - не дата: %v", name, got, errParse)
+		}
+	})
+}
+
+func FuzzCountDetails(f *testing.F) {
+	for _, name := range fuzzNames {
+		f.Add(name)
+	}
+	f.Fuzz(func(t *testing.T, code string) {
+		got, err := CountDetails(code)
+		if err != nil {
+			return
+		}
+		if got == "" || strings.Trim(got, "0123456789") != "" {
+			t.Errorf("CountDetails(%q) = %q - не число", code, got)
+		}
+	})
+}
+
+func FuzzGetPartFromDividedString(f *testing.F) {
+	for _, name := range fuzzNames {
+		f.Add(name, PartID)
+		f.Add(name, PartDetail)
+		f.Add(name, PartDate)
+	}
+	f.Fuzz(func(t *testing.T, name string, flag int) {
+		got, err := GetPartFromDividedString(name, flag)
+		if err != nil {
+			return
+		}
+		if (strings.Contains(got, "_") && flag != PartSuffix) || !strings.Contains(name, got) {
+			t.Errorf("GetPartFromDividedString(%q, %d) = %q - не часть имени", name, flag, got)
+		}
+		if flag == PartDate && len(got) != len(DefaultDateLayout) {
+			t.Errorf("GetPartFromDividedString(%q, PartDate) = %q", name, got)
+		}
+		if utf8.ValidString(name) && !utf8.ValidString(got) {
+			t.Errorf("GetPartFromDividedString(%q, %d) = %q - разрезан символ", name, flag, got)
+		}
+	})
+}
+
+func TestNewNameGrammar(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		namePattern, readyPattern, dateLayout string
+		wantErr                               bool
+	}{
+		{DefaultNamePattern, DefaultReadyPattern, DefaultDateLayout, false},
+		{`^(?P<id>\w+)-(?P<qty>\d+)$`, `^done (?P<date>.+)$`, "2006-01-02", false},
+		{`^(?P<id>\w+)$`, DefaultReadyPattern, DefaultDateLayout, true},                // нет qty
+		{`^(?P<id>\w+)-(?P<count>\d+)$`, DefaultReadyPattern, DefaultDateLayout, true}, // неизвестная группа
+		{DefaultNamePattern, `^done$`, DefaultDateLayout, true},                        // нет date
+		{`^(?P<id>[\w+)-(?P<qty>\d+)$`, DefaultReadyPattern, DefaultDateLayout, true},  // ошибка в шаблоне
+		{DefaultNamePattern, DefaultReadyPattern, "200601", true},                      // нет дня
+		{DefaultNamePattern, DefaultReadyPattern, "yyyymmdd", true},
+	}
+	for _, test := range tests {
+		// Action
+		_, err := NewNameGrammar(test.namePattern, test.readyPattern, test.dateLayout)
+		// Assert
+		if (err != nil) != test.wantErr {
+			t.Errorf("NewNameGrammar(%q, %q, %q) = %v; want error %t", test.namePattern, test.readyPattern, test.dateLayout, err, test.wantErr)
+		}
+	}
+}
+
+func TestCustomNameGrammar(t *testing.T) {
+	// Arrange: имена вида "DET-12 x4 (Белый).xml" и "done 2025-06-01.xml"
+	grammar, err := NewNameGrammar(`^(?P<id>[A-Z]+-[\d.]+) x(?P<qty>\d+)(?: \((?P<material>[^)]*)\))?$`, `^done (?P<date>\d{4}-\d{2}-\d{2})$`, "2006-01-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	id, errID := grammar.GetPartFromDividedString("DET-1.2 x4 (Белый)", PartID)
+	amount, errAmount := grammar.CountDetails("DET-1.2 x4 (Белый)")
+	material, errMaterial := grammar.GetPartFromDividedString("DET-1.2 x4 (Белый)", PartMaterial)
+	date, errDate := grammar.GetReadyDate("done 2025-06-01.xml")
+	// метки программы читаются и при других правилах
+	orderDate, errOrder := grammar.GetReadyDate("order_ready_20250603.xml")
+	_, errOld := grammar.CountDetails("1.2_3_Дверь")
+	// Assert
+	if id != "DET-1.2" || amount != "4" || material != "Белый" || date != "2025-06-01" || orderDate != "2025-06-03" {
+		t.Errorf("got id %q, amount %q, material %q, date %q, order date %q", id, amount, material, date, orderDate)
+	}
+	for _, err := range []error{errID, errAmount, errMaterial, errDate, errOrder} {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if errOld == nil {
+		t.Error("имя по правилам по умолчанию разобрано по другим правилам")
+	}
+}
+
+func TestCheckFileName(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		name        string
+		isJobFile   bool
+		wantChecked bool
+		wantErr     bool
+	}{
+		{"1.2_3_Дверь.xml", true, true, false},
+		{"2_1_Фреза.MPR", true, true, false},
+		{"ready_20250601.xml", false, true, false},
+		{"order_ready_20250631.xml", false, true, true},
+		{"Дверь.xml", true, true, true},
+		{"1_x_Дверь.xml", true, true, true},
+		{"Дверь.cix", true, true, true},
+		{"Дверь.mpr", false, false, false},
+		{"list.xml", false, false, false},
+		{"ready_fasady.xml", false, false, false},
+		{"Эскиз.pdf", false, false, false},
+	}
+	for _, test := range tests {
+		// Action
+		checked, err := defaultGrammar.CheckFileName(test.name, test.isJobFile)
+		// Assert
+		if checked != test.wantChecked || (err != nil) != test.wantErr {
+			t.Errorf("CheckFileName(%q) = %t, %v; want %t, error %t", test.name, checked, err, test.wantChecked, test.wantErr)
+		}
+	}
+}
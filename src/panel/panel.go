@@ -0,0 +1,203 @@
+// Пакет panel - XML-файлы деталей (панелей): чтение, обновление поля Name,
+// подсчёт количества и площади, разбор имён файлов деталей.
+package panel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+)
+
+// XTaskXML: Структура для разбора XML-файлов деталей
+type XTaskXML struct {
+	XMLName xml.Name `xml:"Root"`
+	Project XProject `xml:"Project"`
+}
+
+// XProject: Структура проекта в XML детали
+type XProject struct {
+	Name   string  `xml:"Name,attr"`
+	Flag   string  `xml:"Flag,attr"`
+	Panels XPanels `xml:"Panels"`
+}
+
+// XPanels: Список панелей в XML детали
+type XPanels struct {
+	Panel []XPanel `xml:"Panel"`
+}
+
+// XPanel: Структура панели в XML детали
+type XPanel struct {
+	ID             string `xml:"ID,attr"`
+	Name           string `xml:"Name,attr"` // Это поле будет обновлено
+	Width          string `xml:"Width,attr"`
+	Length         string `xml:"Length,attr"`
+	Material       string `xml:"Material,attr"`
+	Thickness      string `xml:"Thickness,attr"`
+	IsProduce      string `xml:"IsProduce,attr"`
+	MachiningPoint string `xml:"MachiningPoint,attr"`
+	Type           string `xml:"Type,attr"`
+	Face5ID        string `xml:"Face5ID,attr"`
+	Face6ID        string `xml:"Face6ID,attr"`
+	Grain          string `xml:"Grain,attr"`
+	Count          string `xml:"Count,attr"`
+	Machines       string `xml:",innerxml"`
+	EdgeGroup      string `xml:",innerxml"`
+}
+
+// --- Функции обработки файлов и XML ---
+
+/**
+ * UpdateFileWithXML: Читает XML-файл, обновляет поле Name у панелей и перезаписывает файл.
+ * Кодировка файла (UTF-8, UTF-8 с BOM, UTF-16, windows-1251) при перезаписи сохраняется.
+ * @param filePath - Путь к XML-файлу для обновления.
+ */
+func UpdateFileWithXML(filePath string) {
+	myFileBytes, errRead := fileio.ReadFile(filePath)
+	if errRead != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка чтения XML-файла %s: %v"), filePath, errRead), logging.FieldPath, filePath, logging.FieldAction, "update-xml", logging.FieldError, errRead)
+		return
+	}
+	taskXML, err := ParseTaskXML(myFileBytes, filePath)
+	if err != nil {
+		logging.Error(strings.TrimSpace(err.Error()), logging.FieldPath, filePath, logging.FieldAction, "update-xml")
+		return
+	}
+	editedTaskXML, isXmlUpdated := PostprocessXML(taskXML, filePath)
+
+	if isXmlUpdated {
+		// Сериализуем обновленную структуру обратно в XML
+		editedTaskXMLBytes, errMarshal := xml.MarshalIndent(editedTaskXML, "", "	") // Используем табуляцию для отступов
+		if errMarshal != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Ошибка при сериализации обновленного XML: %v"), errMarshal), logging.FieldPath, filePath, logging.FieldAction, "update-xml", logging.FieldError, errMarshal)
+			return
+		}
+		// Перезаписываем файл с обновленным содержимым
+		myHeader := `<?xml version="1.0" encoding="utf-8" ?>` + "\n"
+		// файл записывается в той же кодировке, в какой его выгрузила программа проектирования
+		encodedBytes, errEncode := fileio.EncodeXML([]byte(myHeader+string(editedTaskXMLBytes)), fileio.DetectEncoding(myFileBytes))
+		if errEncode != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Файл %s не обновлён: %v"), filePath, errEncode), logging.FieldPath, filePath, logging.FieldAction, "update-xml", logging.FieldError, errEncode)
+			return
+		}
+		panelCount := len(editedTaskXML.Project.Panels.Panel)
+		errWrite := fileio.CreateVerifiedFile(filePath, encodedBytes, func(data []byte) error {
+			written, err := ParseTaskXML(data, filePath)
+			if err == nil && len(written.Project.Panels.Panel) != panelCount {
+				err = fmt.Errorf(i18n.Tr("в записанном файле %d панелей вместо %d"), len(written.Project.Panels.Panel), panelCount)
+			}
+			return err
+		})
+		if errWrite == nil {
+			logging.Debug(fmt.Sprintf(i18n.Tr("Обновлены имена панелей в %s"), filePath), logging.FieldPath, filePath, logging.FieldAction, "update-xml")
+		}
+	}
+	return
+}
+
+/**
+ * ReadTaskXML: Читает и разбирает XML-файл детали.
+ * @param filePath - Путь к XML-файлу.
+ * @return XTaskXML - Разобранное содержимое файла.
+ * @return error - Ошибка чтения или разбора файла.
+ */
+func ReadTaskXML(filePath string) (XTaskXML, error) {
+	myFileBytes, errRead := fileio.ReadFile(filePath)
+	if errRead != nil {
+		return XTaskXML{}, fmt.Errorf(i18n.Tr("Ошибка чтения XML-файла %s: %w\n"), filePath, errRead)
+	}
+	return ParseTaskXML(myFileBytes, filePath)
+}
+
+// Разбирает содержимое XML-файла детали (см. ReadTaskXML)
+func ParseTaskXML(myFileBytes []byte, filePath string) (XTaskXML, error) {
+	var taskXML XTaskXML
+	decoded, _ := fileio.DecodeXML(myFileBytes)
+	if err := xml.Unmarshal(decoded, &taskXML); err != nil {
+		return taskXML, fmt.Errorf(i18n.Tr("Ошибка при разборе XML-файла %s: %w\n"), filePath, err)
+	}
+	return taskXML, nil
+}
+
+/**
+ * PostprocessXML: Разбирает XML байты, обновляет поле Name у панелей и возвращает обновленные XML-байты.
+ * @param root - Содержимое XML-файла в виде байтов.
+ * @param filePath - Путь к XML-файлу, для сообщений в журнале.
+ * @return XTaskXML - Обновленное XML-содержимое в виде массива байт.
+ * @return bool - true, если данные обновлены.
+ */
+func PostprocessXML(root XTaskXML, filePath string) (updatedXML XTaskXML, isUpdated bool) {
+	isUpdated = false // Флаг, что хотя бы одно имя было обновлено
+	for i := range root.Project.Panels.Panel {
+		panel := &root.Project.Panels.Panel[i]
+		width64, errW := strconv.ParseFloat(strings.Replace(panel.Width, ",", ".", 1), 64)
+		length64, errL := strconv.ParseFloat(strings.Replace(panel.Length, ",", ".", 1), 64)
+		thickness64, errT := strconv.ParseFloat(strings.Replace(panel.Thickness, ",", ".", 1), 64)
+
+		if errW != nil || errL != nil || errT != nil {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Предупреждение: Не удалось преобразовать Длину ('%s'), Ширину ('%s') или Толщину ('%s') в число для панели ID='%s'. Имя не будет обновлено."), panel.Length, panel.Width, panel.Thickness, panel.ID),
+				logging.FieldPath, filePath, logging.FieldAction, "update-xml", "panel", panel.ID)
+		} else {
+			// Используем .0f, чтоб не было знаков после запятой
+			newName := fmt.Sprintf("%.0f_%.0f_%.0f", length64, width64, thickness64)
+			if panel.Name != newName {
+				panel.Name = newName
+				isUpdated = true
+			}
+		}
+	}
+	return root, isUpdated
+}
+
+/**
+ * CountAndArea: Возвращает количество панелей и их общую площадь в м².
+ * Если количество не указано или некорректно, считается одна панель.
+ * @return bool - false, если не удалось разобрать длину или ширину.
+ */
+func (panel *XPanel) CountAndArea() (int, float64, bool) {
+	width64, errW := strconv.ParseFloat(strings.Replace(panel.Width, ",", ".", 1), 64)
+	length64, errL := strconv.ParseFloat(strings.Replace(panel.Length, ",", ".", 1), 64)
+	if errW != nil || errL != nil {
+		return 0, 0, false
+	}
+	count, errC := strconv.Atoi(panel.Count)
+	if errC != nil || count < 1 {
+		count = 1
+	}
+	return count, width64 * length64 / 1e6 * float64(count), true
+}
+
+/**
+ * FolderPanelTotals: Суммирует панели во всех XML-файлах деталей папки (без вложенных папок).
+ * @return int - Количество панелей.
+ * @return float64 - Площадь панелей, м².
+ */
+func FolderPanelTotals(dirPath string) (int, float64) {
+	entries, err := fileio.ReadDir(dirPath)
+	if err != nil {
+		return 0, 0
+	}
+	panels, area := 0, 0.0
+	for _, entry := range entries {
+		if entry.IsDir() || fileio.GetExtension(entry.Name()) != "xml" || HasStopWord(entry.Name()) {
+			continue
+		}
+		taskXML, err := ReadTaskXML(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, panel := range taskXML.Project.Panels.Panel {
+			if count, panelArea, ok := panel.CountAndArea(); ok {
+				panels += count
+				area += panelArea
+			}
+		}
+	}
+	return panels, area
+}
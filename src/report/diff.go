@@ -0,0 +1,153 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Изменения между двумя отчётами о работе
+type ReportChanges struct {
+	newOrders      []string // Заказы, появившиеся в новом отчёте
+	readyOrders    []string // Заказы, ставшие готовыми
+	archivedOrders []string // Заказы, исчезнувшие из папки (перемещены в архив или удалены)
+	attention      []string // Папки, которым впервые потребовалось участие пользователя
+	progress       []string // Изменения доли готовых раскроев у заказов в работе
+}
+
+/**
+ * Compare: Сравнивает два отчёта о работе по заказам (уровень order иерархии из настроек) и всем вложенным папкам.
+ * Заказы сопоставляются по пути от стартовой папки (заказчик/заказ), поэтому заказ, перемещённый из папки
+ * заказчика с другими заказами, тоже попадает в изменения. В сохранённых отчётах уровней нет,
+ * они проставляются обоим отчётам по настройкам.
+ * @param oldReports - Предыдущий отчёт.
+ * @param newReports - Текущий отчёт.
+ * @param settings - Настройки с описанием иерархии.
+ * @return ReportChanges - Найденные изменения, каждый список отсортирован.
+ */
+func Compare(oldReports, newReports []walker.ReportObj, settings config.Settings) ReportChanges {
+	var changes ReportChanges
+	oldByPath := ordersByPath(oldReports, &settings)
+	newByPath := ordersByPath(newReports, &settings)
+
+	for path, rep := range newByPath {
+		old, existed := oldByPath[path]
+		if !existed {
+			changes.newOrders = append(changes.newOrders, fmt.Sprintf("%s (%s)", path, walker.StatusName(rep.Status)))
+		}
+		if rep.Status == walker.StatusReady && (!existed || old.Status != walker.StatusReady) {
+			changes.readyOrders = append(changes.readyOrders, fmt.Sprintf("%s (%s)", path, rep.DateReady))
+		}
+		if existed && rep.Status == walker.StatusPending && old.Status == walker.StatusPending {
+			oldReady, oldTotal := countLeaves(old)
+			newReady, newTotal := countLeaves(rep)
+			if oldReady != newReady || oldTotal != newTotal {
+				changes.progress = append(changes.progress,
+					fmt.Sprintf("%s: %d/%d → %d/%d", path, oldReady, oldTotal, newReady, newTotal))
+			}
+		}
+	}
+	for path := range oldByPath {
+		if _, exists := newByPath[path]; !exists {
+			changes.archivedOrders = append(changes.archivedOrders, path)
+		}
+	}
+
+	oldPaths := make(map[string]string)
+	for _, rep := range oldReports {
+		collectStatuses(rep, "", oldPaths)
+	}
+	newPaths := make(map[string]string)
+	for _, rep := range newReports {
+		collectStatuses(rep, "", newPaths)
+	}
+	for path, status := range newPaths {
+		if status == walker.StatusOther && oldPaths[path] != walker.StatusOther {
+			changes.attention = append(changes.attention, path)
+		}
+	}
+
+	sort.Strings(changes.newOrders)
+	sort.Strings(changes.readyOrders)
+	sort.Strings(changes.archivedOrders)
+	sort.Strings(changes.attention)
+	sort.Strings(changes.progress)
+	return changes
+}
+
+// Заказы дерева отчётов по пути от стартовой папки ("заказчик/заказ"); уровни проставляются по настройкам
+func ordersByPath(reports []walker.ReportObj, settings *config.Settings) map[string]walker.ReportObj {
+	for i := range reports {
+		reports[i].AssignLevels(1, settings)
+	}
+	result := make(map[string]walker.ReportObj)
+	for _, ref := range walker.CollectOrders(reports) {
+		result[strings.Join(ref.Names(), "/")] = ref.Item
+	}
+	return result
+}
+
+// Подсчитывает готовые и все конечные папки (раскрои) в дереве отчёта
+func countLeaves(item walker.ReportObj) (ready int, total int) {
+	if len(item.InnerItems) == 0 {
+		if item.Status == walker.StatusReady {
+			return 1, 1
+		}
+		return 0, 1
+	}
+	for _, inner := range item.InnerItems {
+		r, t := countLeaves(inner)
+		ready += r
+		total += t
+	}
+	return ready, total
+}
+
+// Собирает статусы всех папок дерева отчёта с путями вида "заказчик/заказ/проект"
+func collectStatuses(item walker.ReportObj, parent string, statuses map[string]string) {
+	path := item.ItemName
+	if parent != "" {
+		path = parent + "/" + item.ItemName
+	}
+	statuses[path] = item.Status
+	for _, inner := range item.InnerItems {
+		collectStatuses(inner, path, statuses)
+	}
+}
+
+/**
+ * isEmpty: Возвращает true, если изменений нет.
+ */
+func (changes *ReportChanges) isEmpty() bool {
+	return len(changes.newOrders) == 0 && len(changes.readyOrders) == 0 && len(changes.archivedOrders) == 0 &&
+		len(changes.attention) == 0 && len(changes.progress) == 0
+}
+
+/**
+ * String: Формирует текстовое описание изменений для вывода и сохранения в файл.
+ */
+func (changes ReportChanges) String() string {
+	if changes.isEmpty() {
+		return i18n.Tr("Изменений нет\n")
+	}
+	var sb strings.Builder
+	writeSection := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		sb.WriteString(title + ":\n")
+		for _, line := range lines {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	writeSection(i18n.Tr("Новые заказы"), changes.newOrders)
+	writeSection(i18n.Tr("Стали готовыми"), changes.readyOrders)
+	writeSection(i18n.Tr("Перемещены в архив"), changes.archivedOrders)
+	writeSection(i18n.Tr("Требуют участия пользователя"), changes.attention)
+	writeSection(i18n.Tr("Изменение готовности"), changes.progress)
+	return sb.String()
+}
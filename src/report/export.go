@@ -0,0 +1,91 @@
+package report
+
+import (
+	"encoding/csv"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Метка порядка байтов, по которой Excel распознаёт CSV в кодировке UTF-8
+const utf8BOM = "\uFEFF"
+
+// Столбцы выгрузки после столбцов уровней иерархии
+var exportHeaderTail = []string{"Статус", "Дата готовности", "Панелей", "Площадь, м²", "Папка"}
+
+/**
+ * CreateCSV: Формирует выгрузку отчёта для Excel: CSV в UTF-8 с BOM, разделитель ";".
+ * Одна строка на конечную папку (раскрой); имена папок раскладываются по столбцам уровней иерархии
+ * (заказчик, заказ, проект, материал - как описано в настройках), папки без уровня дописываются
+ * через "/" к вышестоящему столбцу.
+ * @param reports - Отчёты по папкам верхнего уровня с проставленными уровнями иерархии.
+ * @param locations - Расположение папок с учётом перемещения в архив.
+ * @param settings - Настройки программы (описание иерархии).
+ * @return string - Содержимое CSV-файла.
+ * @return error - Ошибка формирования CSV.
+ */
+func CreateCSV(reports []walker.ReportObj, locations archive.FolderLocations, settings config.Settings) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(utf8BOM)
+	w := csv.NewWriter(&sb)
+	w.Comma = ';'
+	w.UseCRLF = true
+	var header []string
+	for _, level := range settings.Hierarchy {
+		header = append(header, settings.LevelTitle(level.Kind))
+	}
+	w.Write(append(header, i18n.TrAll(exportHeaderTail)...))
+	for _, rep := range reports {
+		writeCSVRows(w, rep, nil, locations, &settings)
+	}
+	w.Flush()
+	return sb.String(), w.Error()
+}
+
+/**
+ * writeCSVRows: Рекурсивно выводит строки выгрузки для конечных папок.
+ * @param w - CSV-писатель.
+ * @param item - Отчёт о папке.
+ * @param parents - Вышестоящие папки, начиная с верхнего уровня.
+ * @param locations - Расположение папок с учётом перемещения в архив.
+ * @param settings - Настройки программы (описание иерархии).
+ */
+func writeCSVRows(w *csv.Writer, item walker.ReportObj, parents []walker.ReportObj, locations archive.FolderLocations, settings *config.Settings) {
+	chain := append(append([]walker.ReportObj{}, parents...), item)
+	if len(item.InnerItems) > 0 {
+		for _, inner := range item.InnerItems {
+			writeCSVRows(w, inner, chain, locations, settings)
+		}
+		return
+	}
+
+	columns := make([]string, len(settings.Hierarchy))
+	names := make([]string, len(chain))
+	last := 0
+	for i, node := range chain {
+		names[i] = node.ItemName
+		col := -1
+		for j, level := range settings.Hierarchy {
+			if level.Kind == node.Kind {
+				col = j
+				break
+			}
+		}
+		if col < 0 {
+			col = last
+		}
+		if columns[col] != "" {
+			columns[col] += "/"
+		}
+		columns[col] += node.ItemName
+		last = col
+	}
+	dirPath := locations.Locate(filepath.Join(names...))
+	panels, area := locations.PanelTotals(filepath.Join(names...))
+	w.Write(append(columns, walker.StatusName(item.Status), item.DateReady, strconv.Itoa(panels), formatDecimal(area, 2), dirPath))
+}
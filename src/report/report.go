@@ -0,0 +1,138 @@
+// Пакет report - отчёты по дереву ReportObj: текстовый, HTML, CSV, сравнение отчётов и статистика архива.
+package report
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+/**
+ * CreateText: Формирует текстовый отчёт - по строке на заказ: месяц готовности и путь к заказу.
+ * @param reports - Отчёты по папкам верхнего уровня с проставленными уровнями иерархии.
+ * @param settings - Настройки программы.
+ * @return string - Текст отчёта, отсортированный по месяцу.
+ */
+func CreateText(reports []walker.ReportObj, settings config.Settings) string {
+	var reportStrings []string
+	for _, ref := range walker.CollectOrders(reports) {
+		dateMonth := ""
+		if ready, err := time.Parse(time.DateOnly, ref.Item.DateReady); err == nil {
+			dateMonth = ready.Format("2006-01")
+		}
+		name := strings.Join(ref.Names(), " / ")
+		reportStrings = append(reportStrings, dateMonth+" - "+name+"\n")
+	}
+	sort.Strings(reportStrings)
+	var sb strings.Builder
+	for _, rs := range reportStrings {
+		sb.WriteString(rs)
+	}
+	return sb.String()
+}
+
+/**
+ * CreateHTML: Формирует HTML-отчёт с раскрывающимся деревом (заказчик → заказ → проект → раскрой).
+ * Статусы выделены цветом, у каждой папки есть ссылка для открытия в Проводнике.
+ * @param reports - Отчёты по папкам верхнего уровня с проставленными уровнями иерархии.
+ * @param locations - Расположение папок с учётом перемещения в архив.
+ * @param settings - Настройки программы (названия уровней иерархии).
+ * @return string - Содержимое HTML-файла.
+ */
+func CreateHTML(reports []walker.ReportObj, locations archive.FolderLocations, settings config.Settings) string {
+	sorted := make([]walker.ReportObj, len(reports))
+	copy(sorted, reports)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ItemName < sorted[j].ItemName
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n", i18n.Language())
+	fmt.Fprintf(&sb, "<title>%s</title>\n", i18n.Tr("Отчёт о работе"))
+	sb.WriteString("<style>body{font-family:sans-serif}details{margin-left:1.5em}summary{cursor:pointer}" +
+		"p.leaf{margin:0 0 0 3em}.status{font-weight:bold;padding:0 4px}.kind{color:#777}" +
+		".ready{color:#1a7f1a}.pending{color:#c77c00}.other{color:#c00000}.date{color:#555}</style>\n")
+	// свёрнутые готовые ветки раскрываются перед печатью: содержимое закрытого <details> CSS не показывает
+	sb.WriteString("<script>window.addEventListener(\"beforeprint\",function(){" +
+		"document.querySelectorAll(\"details\").forEach(function(d){d.open=true})})</script>\n")
+	fmt.Fprintf(&sb, "</head>\n<body>\n<h1>%s</h1>\n", i18n.Tr("Отчёт о работе"))
+	for _, rep := range sorted {
+		writeHTMLItem(&sb, rep, rep.ItemName, locations, &settings, true)
+	}
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+/**
+ * writeHTMLItem: Добавляет в HTML-отчёт строку папки и, рекурсивно, её вложенных папок.
+ * @param sb - Построитель строки отчёта.
+ * @param item - Отчёт о папке.
+ * @param relPath - Путь к папке относительно стартовой.
+ * @param locations - Расположение папок с учётом перемещения в архив.
+ * @param settings - Настройки программы (названия уровней иерархии).
+ * @param open - true, если узел должен быть раскрыт при открытии отчёта.
+ */
+func writeHTMLItem(sb *strings.Builder, item walker.ReportObj, relPath string, locations archive.FolderLocations, settings *config.Settings, open bool) {
+	line := htmlLine(item, locations.Locate(relPath))
+	if item.Kind != "" {
+		line = fmt.Sprintf("<span class=\"kind\">%s:</span> %s", html.EscapeString(settings.LevelTitle(item.Kind)), line)
+	}
+	if len(item.InnerItems) == 0 {
+		fmt.Fprintf(sb, "<p class=\"leaf\">%s</p>\n", line)
+		return
+	}
+	openAttr := ""
+	if open {
+		openAttr = " open"
+	}
+	fmt.Fprintf(sb, "<details%s><summary>%s</summary>\n", openAttr, line)
+	for _, inner := range item.InnerItems {
+		// готовые ветки свёрнуты, чтобы внимание было на незавершённом
+		writeHTMLItem(sb, inner, filepath.Join(relPath, inner.ItemName), locations, settings, inner.Status != walker.StatusReady)
+	}
+	sb.WriteString("</details>\n")
+}
+
+// Формирует строку узла HTML-отчёта: имя (ссылка на папку), статус и дата готовности
+func htmlLine(item walker.ReportObj, dirPath string) string {
+	name := html.EscapeString(item.ItemName)
+	if dirPath != "" {
+		name = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(fileURL(dirPath)), name)
+	}
+	line := fmt.Sprintf("%s <span class=\"status %s\">%s</span>", name, statusClass(item.Status), html.EscapeString(walker.StatusName(item.Status)))
+	if item.DateReady != "" {
+		line += fmt.Sprintf(" <span class=\"date\">%s</span>", html.EscapeString(item.DateReady))
+	}
+	return line
+}
+
+// Возвращает CSS-класс для статуса папки
+func statusClass(status string) string {
+	switch status {
+	case walker.StatusReady:
+		return "ready"
+	case walker.StatusPending:
+		return "pending"
+	default:
+		return "other"
+	}
+}
+
+// Преобразует путь к папке в ссылку file:// (в том числе для путей вида D:\... и \\server\share)
+func fileURL(dirPath string) string {
+	slashed := filepath.ToSlash(dirPath)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	u := url.URL{Scheme: "file", Path: slashed}
+	return u.String()
+}
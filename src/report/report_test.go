@@ -0,0 +1,199 @@
+package report_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+	"github.com/ProOwler/ListMaker/fixture/fixturetest"
+	"github.com/ProOwler/ListMaker/report"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Строит в памяти синтетическое дерево, обходит его и перемещает готовые заказы в архив
+func walkGenerated(t *testing.T, seed int64) ([]walker.ReportObj, archive.FolderLocations, config.Settings) {
+	t.Helper()
+	fixturetest.Mem(t)
+	work := fixture.New("/work")
+	var settings config.Settings
+	if err := settings.ReadFromFile(work.Settings("listMaker_settings.xml", "./src", "./done")); err != nil {
+		t.Fatal(err)
+	}
+	tree := fixture.Generate(settings.DirSource, fixture.Spec{Customers: 3, Orders: 2, Projects: 2, Materials: 2, Fasady: true, Seed: seed})
+	// готовый заказ перемещается в архив
+	tree.Panel("Кузнецова/Кухня/ЛДСП Белый/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Кузнецова/Кухня/ЛДСП Белый", "20250612")
+	tree.Panel("Кузнецова/Кухня/МДФ/1_1_Фасад.xml", 716, 396, 1)
+	tree.Ready("Кузнецова/Кухня/МДФ", "20250615")
+	work.Dir("done")
+	reports := walker.Walk(settings.DirSource, settings).InnerItems
+	for i := range reports {
+		reports[i].AssignLevels(1, &settings)
+	}
+	return reports, archive.MoveReadyOrders(settings.DirSource, reports, settings), settings
+}
+
+func TestReports(t *testing.T) {
+	// Arrange
+	reports, locations, settings := walkGenerated(t, 7)
+	// Action
+	text := report.CreateText(reports, settings)
+	html := report.CreateHTML(reports, locations, settings)
+	csv, err := report.CreateCSV(reports, locations, settings)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixturetest.Golden(t, "report.txt", []byte(text))
+	fixturetest.Golden(t, "report.html", []byte(html))
+	fixturetest.Golden(t, "report.csv", []byte(csv))
+}
+
+func TestCompare(t *testing.T) {
+	// Arrange
+	oldReports := []walker.ReportObj{
+		{ItemName: "Иванов", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+			{ItemName: "Кухня", Status: walker.StatusReady, DateReady: "2025-06-01"},
+			{ItemName: "Шкаф", Status: walker.StatusPending},
+		}},
+		{ItemName: "Петров", Status: walker.StatusReady, DateReady: "2025-05-20"},
+	}
+	newReports := []walker.ReportObj{
+		{ItemName: "Иванов", Status: walker.StatusReady, DateReady: "2025-06-03", InnerItems: []walker.ReportObj{
+			{ItemName: "Кухня", Status: walker.StatusReady, DateReady: "2025-06-01"},
+			{ItemName: "Шкаф", Status: walker.StatusReady, DateReady: "2025-06-03"},
+		}},
+		{ItemName: "Сидоров", Status: walker.StatusOther},
+	}
+	// заказы внутри папок заказчиков: заказ перемещён в архив, а заказчик остался
+	customers := config.Settings{Hierarchy: []config.HierarchyLevel{{Kind: config.LevelCustomer, Depth: 1}, {Kind: config.LevelOrder, Depth: 2}}}
+	oldCustomers := []walker.ReportObj{
+		{ItemName: "Иванов", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+			{ItemName: "Кухня", Status: walker.StatusReady, DateReady: "2025-06-01"},
+			{ItemName: "Шкаф", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+				{ItemName: "ЛДСП", Status: walker.StatusPending},
+				{ItemName: "МДФ", Status: walker.StatusReady, DateReady: "2025-06-02"},
+			}},
+		}},
+		{ItemName: "Петров", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+			{ItemName: "Прихожая", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+				{ItemName: "ЛДСП", Status: walker.StatusPending},
+				{ItemName: "МДФ", Status: walker.StatusPending},
+			}},
+		}},
+	}
+	newCustomers := []walker.ReportObj{
+		{ItemName: "Иванов", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+			{ItemName: "Спальня", Status: walker.StatusPending},
+			{ItemName: "Шкаф", Status: walker.StatusReady, DateReady: "2025-06-05", InnerItems: []walker.ReportObj{
+				{ItemName: "ЛДСП", Status: walker.StatusReady, DateReady: "2025-06-05"},
+				{ItemName: "МДФ", Status: walker.StatusReady, DateReady: "2025-06-02"},
+			}},
+		}},
+		{ItemName: "Петров", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+			{ItemName: "Прихожая", Status: walker.StatusPending, InnerItems: []walker.ReportObj{
+				{ItemName: "ЛДСП", Status: walker.StatusReady, DateReady: "2025-06-04"},
+				{ItemName: "МДФ", Status: walker.StatusPending},
+			}},
+		}},
+	}
+	// Action
+	changes := report.Compare(oldReports, newReports, config.Settings{})
+	customerChanges := report.Compare(oldCustomers, newCustomers, customers)
+	// Assert
+	fixturetest.Golden(t, "compare.txt", []byte(changes.String()))
+	fixturetest.Golden(t, "compare_customers.txt", []byte(customerChanges.String()))
+	if got := report.Compare(newReports, newReports, config.Settings{}).String(); got != report.Compare(nil, nil, config.Settings{}).String() {
+		t.Errorf("одинаковые отчёты дают изменения:\n%s", got)
+	}
+}
+
+func TestCreateStatusTree(t *testing.T) {
+	// Arrange
+	fixturetest.Mem(t)
+	var settings config.Settings
+	if err := settings.ReadFromFile(fixture.New("/work").Settings("listMaker_settings.xml", "./src", "./done")); err != nil {
+		t.Fatal(err)
+	}
+	tree := fixture.New(settings.DirSource)
+	tree.Panel("Заказ 1/Кухня/ЛДСП Белый/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Заказ 1/Кухня/ЛДСП Белый", "20250601")
+	tree.Panel("Заказ 1/Кухня/МДФ/1_4_Фасад.xml", 716, 396, 4)
+	tree.Panel("Заказ 1/Кухня/МДФ/2_1_Цоколь.xml", 800, 100, 1)
+	tree.List("Заказ 1/Кухня/МДФ", "1_4_Фасад.xml", "2_1_Цоколь.xml")
+	tree.Panel("Заказ 1/Шкаф/ЛДСП Дуб/1_2_Бок.xml", 2000, 600, 2)
+	tree.Panel("Заказ 2/Прихожая/ЛДСП Серый/3_1_Полка.xml", 600, 300, 1)
+	tree.Ready("Заказ 2/Прихожая/ЛДСП Серый", "20250520")
+	// станок уже сделал 3 фасада из 4
+	listPath := tree.Path("Заказ 1/Кухня/МДФ/list.xml")
+	data, err := fileio.ReadFile(listPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fileio.WriteFile(listPath, []byte(strings.Replace(string(data), "<Count>0</Count>", "<Count>3</Count>", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reports := walker.WalkReadOnly(settings.DirSource, settings).InnerItems
+	for i := range reports {
+		reports[i].AssignLevels(1, &settings)
+	}
+	// Action
+	plain := report.CreateStatusTree(reports, settings.DirSource, settings, false, false)
+	all := report.CreateStatusTree(reports, settings.DirSource, settings, false, true)
+	colored := report.CreateStatusTree(reports, settings.DirSource, settings, true, false)
+	// Assert
+	fixturetest.Golden(t, "status.txt", []byte(plain))
+	fixturetest.Golden(t, "status_all.txt", []byte(all))
+	if !strings.Contains(colored, "\033[32m") || !strings.Contains(colored, "\033[0m") || strings.Contains(plain, "\033[") {
+		t.Errorf("цвета в дереве статусов:\n%q\n%q", colored, plain)
+	}
+}
+
+// Средний срок считается в календарных днях: заказ, файлы которого менялись в день готовности, учитывается с 0 дней
+func TestWriteStatisticsLeadTime(t *testing.T) {
+	// Arrange
+	fixturetest.Mem(t)
+	var settings config.Settings
+	if err := settings.ReadFromFile(fixture.New("/work").Settings("listMaker_settings.xml", "./src", "./done")); err != nil {
+		t.Fatal(err)
+	}
+	done := fixture.New(settings.DirTarget)
+	for _, order := range []struct {
+		rel     string
+		ready   string
+		started time.Time
+	}{
+		{"2025-06/Иванов", "2025-06-10", time.Date(2025, 6, 1, 15, 0, 0, 0, time.Local)},
+		{"2025-06/Петров", "2025-06-10", time.Date(2025, 6, 10, 14, 0, 0, 0, time.Local)},
+		{"2025-07/Сидоров", "2025-07-05", time.Date(2025, 7, 1, 9, 0, 0, 0, time.Local)},
+	} {
+		done.Panel(order.rel+"/ЛДСП/1_2_Бок.xml", 700, 400, 2)
+		done.OrderMarker(order.rel, walker.ReportObj{ItemName: filepath.Base(order.rel), DateReady: order.ready, Status: walker.StatusReady})
+		if err := fileio.Chtimes(done.Path(order.rel+"/ЛДСП/1_2_Бок.xml"), order.started); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Action
+	report.WriteStatistics(settings, nil)
+	// Assert
+	data, err := fileio.ReadFile(filepath.Join(settings.DirTarget, settings.FileStatistics+".csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lead := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		if fields := strings.Split(line, ";"); len(fields) > 3 {
+			lead[fields[0]] = fields[1] + " заказов, " + fields[3] + " дн."
+		}
+	}
+	want := map[string]string{"2025-06": "2 заказов, 4,5 дн.", "2025-07": "1 заказов, 4,0 дн."}
+	if !reflect.DeepEqual(lead, want) {
+		t.Errorf("срок по месяцам: %v; want %v", lead, want)
+	}
+}
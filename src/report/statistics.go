@@ -0,0 +1,367 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io/fs"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/walker"
+)
+
+// Статистика производства за один месяц
+type MonthStats struct {
+	month     string                    // Месяц в формате yyyy-mm
+	orders    int                       // Количество выполненных заказов
+	projects  int                       // Количество выполненных проектов
+	leadDays  float64                   // Суммарный срок выполнения заказов, дней
+	leadCount int                       // Количество заказов, для которых удалось вычислить срок
+	materials map[string]*MaterialStats // Раскрой по материалам
+}
+
+// Раскрой одного материала
+type MaterialStats struct {
+	panels int     // Количество панелей
+	area   float64 // Площадь панелей, м²
+}
+
+// Сводная статистика: по месяцам и незавершённые заказы
+type ProductionStats struct {
+	months  []MonthStats
+	pending int // Заказы в работе (статус ОЖИДАЕТ)
+	other   int // Заказы, требующие участия пользователя (статус ИНОЕ)
+}
+
+/**
+ * averageLeadDays: Возвращает средний срок выполнения заказа в днях.
+ * @return float64 - Средний срок или 0, если срок не вычислен ни для одного заказа.
+ */
+func (ms *MonthStats) averageLeadDays() float64 {
+	if ms.leadCount == 0 {
+		return 0
+	}
+	return ms.leadDays / float64(ms.leadCount)
+}
+
+/**
+ * sortedMaterials: Возвращает названия материалов месяца в алфавитном порядке.
+ */
+func (ms *MonthStats) sortedMaterials() []string {
+	var names []string
+	for name := range ms.materials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+ * collectStatistics: Собирает статистику по меткам order_ready_*.xml заказов в архиве (TargetDir).
+ * @param reports - Отчёты текущего запуска с уровнями иерархии (для подсчёта незавершённых заказов).
+ * @param settings - Настройки программы (TargetDir и описание иерархии).
+ * @return ProductionStats - Статистика, месяцы отсортированы по возрастанию.
+ */
+func collectStatistics(reports []walker.ReportObj, settings config.Settings) ProductionStats {
+	var result ProductionStats
+	dirTarget := settings.DirTarget
+	for _, ref := range walker.CollectOrders(reports) {
+		switch ref.Item.Status {
+		case walker.StatusPending:
+			result.pending++
+		case walker.StatusOther:
+			result.other++
+		}
+	}
+
+	byMonth := make(map[string]*MonthStats)
+	for _, orderRel := range archive.FindArchivedOrders(dirTarget, ".") {
+		if fileio.GetExtension(orderRel) == "zip" {
+			addZipOrderStatistics(filepath.Join(dirTarget, orderRel), byMonth, &settings)
+		} else {
+			addOrderStatistics(filepath.Join(dirTarget, orderRel), byMonth, &settings)
+		}
+	}
+
+	for _, ms := range byMonth {
+		result.months = append(result.months, *ms)
+	}
+	sort.Slice(result.months, func(i, j int) bool {
+		return result.months[i].month < result.months[j].month
+	})
+	return result
+}
+
+/**
+ * addOrderStatistics: Добавляет в статистику один архивный заказ.
+ * Месяц определяется по дате готовности из метки, а не по папке архива.
+ * @param orderDir - Папка заказа в архиве.
+ * @param byMonth - Статистика по месяцам для пополнения.
+ * @param settings - Настройки программы (описание иерархии).
+ */
+func addOrderStatistics(orderDir string, byMonth map[string]*MonthStats, settings *config.Settings) {
+	markerPath := walker.FindOrderMarker(orderDir)
+	if markerPath == "" {
+		return
+	}
+	order, err := walker.ReadOrderMarker(markerPath)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не учтён в статистике: %v"), orderDir, err), logging.FieldPath, orderDir, logging.FieldAction, "statistics", logging.FieldError, err)
+		return
+	}
+	ms := monthStatsFor(order, markerPath, byMonth, settings)
+	if ms == nil {
+		return
+	}
+
+	started := time.Time{}
+	fileio.WalkDir(orderDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		// метки пишутся при готовности и на начало работы не указывают
+		if info, errInfo := d.Info(); errInfo == nil && !walker.IsOrderMarkerName(d.Name()) {
+			if started.IsZero() || info.ModTime().Before(started) {
+				started = info.ModTime()
+			}
+		}
+		if fileio.GetExtension(path) == "xml" && !panel.HasStopWord(d.Name()) {
+			if taskXML, err := panel.ReadTaskXML(path); err == nil {
+				addPanelStatistics(taskXML, filepath.Base(filepath.Dir(path)), ms)
+			}
+		}
+		return nil
+	})
+	ms.addLeadTime(order.DateReady, started)
+}
+
+/**
+ * addZipOrderStatistics: Добавляет в статистику заказ, упакованный в zip-архив.
+ * @param zipPath - Путь к zip-файлу заказа.
+ * @param byMonth - Статистика по месяцам для пополнения.
+ * @param settings - Настройки программы (описание иерархии).
+ */
+func addZipOrderStatistics(zipPath string, byMonth map[string]*MonthStats, settings *config.Settings) {
+	zipReader, err := fileio.OpenZip(zipPath)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не учтён в статистике: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "statistics", logging.FieldError, err)
+		return
+	}
+	manifest, err := archive.ReadZipManifest(zipReader)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не учтён в статистике: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "statistics", logging.FieldError, err)
+		return
+	}
+	markerData, err := archive.ReadZipEntry(zipReader, manifest.Marker)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не учтён в статистике: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "statistics", logging.FieldError, err)
+		return
+	}
+	order, err := walker.ParseOrderMarker(markerData, zipPath+"/"+manifest.Marker)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Заказ %s не учтён в статистике: %v"), zipPath, err), logging.FieldPath, zipPath, logging.FieldAction, "statistics", logging.FieldError, err)
+		return
+	}
+	ms := monthStatsFor(order, zipPath, byMonth, settings)
+	if ms == nil {
+		return
+	}
+
+	started := time.Time{}
+	for _, entry := range zipReader.File {
+		if entry.FileInfo().IsDir() || entry.Name == archive.ZipManifestName {
+			continue
+		}
+		if !walker.IsOrderMarkerName(path.Base(entry.Name)) && (started.IsZero() || entry.Modified.Before(started)) {
+			started = entry.Modified
+		}
+		if fileio.GetExtension(entry.Name) == "xml" && !panel.HasStopWord(path.Base(entry.Name)) {
+			if data, err := archive.ReadZipEntry(zipReader, entry.Name); err == nil {
+				if taskXML, err := panel.ParseTaskXML(data, entry.Name); err == nil {
+					addPanelStatistics(taskXML, path.Base(path.Dir(entry.Name)), ms)
+				}
+			}
+		}
+	}
+	ms.addLeadTime(order.DateReady, started)
+}
+
+/**
+ * monthStatsFor: Учитывает готовый заказ в статистике месяца его готовности (заказы и проекты).
+ * Месяц определяется по дате готовности из метки, а не по папке архива.
+ * @param order - Отчёт из метки заказа.
+ * @param source - Откуда прочитана метка (для сообщений).
+ * @param byMonth - Статистика по месяцам для пополнения.
+ * @param settings - Настройки программы (описание иерархии).
+ * @return *MonthStats - Статистика месяца или nil, если заказ не готов.
+ */
+func monthStatsFor(order walker.ReportObj, source string, byMonth map[string]*MonthStats, settings *config.Settings) *MonthStats {
+	if order.Status != walker.StatusReady {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Метка %s не содержит сведений о готовом заказе"), source), logging.FieldPath, source, logging.FieldAction, "statistics")
+		return nil
+	}
+	ready, err := time.Parse(time.DateOnly, order.DateReady)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Метка %s: некорректная дата готовности %q"), source, order.DateReady), logging.FieldPath, source, logging.FieldAction, "statistics")
+		return nil
+	}
+	month := ready.Format("2006-01")
+	ms, ok := byMonth[month]
+	if !ok {
+		ms = &MonthStats{month: month, materials: make(map[string]*MaterialStats)}
+		byMonth[month] = ms
+	}
+	ms.orders++
+	// проекты - вложенные папки уровня project, а если он не описан - папки, непосредственно вложенные в заказ
+	order.AssignLevels(settings.LevelDepth(config.LevelOrder), settings)
+	if settings.HasLevel(config.LevelProject) {
+		ms.projects += order.CountKind(config.LevelProject)
+	} else {
+		ms.projects += len(order.InnerItems)
+	}
+	return ms
+}
+
+/**
+ * addLeadTime: Учитывает срок выполнения заказа - от самого раннего изменения его файлов до даты готовности.
+ * Срок считается в календарных днях: заказ, файлы которого менялись в день готовности, выполнен за 0 дней.
+ * @param dateReady - Дата готовности, yyyy-mm-dd.
+ * @param started - Время самого раннего изменения файлов заказа.
+ */
+func (ms *MonthStats) addLeadTime(dateReady string, started time.Time) {
+	ready, err := time.ParseInLocation(time.DateOnly, dateReady, time.Local)
+	if err != nil || started.IsZero() {
+		return
+	}
+	started = started.In(time.Local)
+	startedDay := time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, time.Local)
+	// округление - переход на летнее время делает сутки на час короче или длиннее
+	lead := math.Round(ready.Sub(startedDay).Hours() / 24)
+	if lead < 0 {
+		// файлы изменены уже после готовности - заказ не выпадает из среднего
+		lead = 0
+	}
+	ms.leadDays += lead
+	ms.leadCount++
+}
+
+/**
+ * addPanelStatistics: Учитывает панели из XML-файла детали в раскрое по материалам.
+ * Если у панели не указан материал, используется имя папки раскроя.
+ * @param taskXML - Разобранный XML-файл детали.
+ * @param folderMaterial - Имя папки, в которой лежит файл.
+ * @param ms - Статистика месяца для пополнения.
+ */
+func addPanelStatistics(taskXML panel.XTaskXML, folderMaterial string, ms *MonthStats) {
+	for _, panel := range taskXML.Project.Panels.Panel {
+		count, area, ok := panel.CountAndArea()
+		if !ok {
+			continue
+		}
+		material := strings.TrimSpace(panel.Material)
+		if material == "" {
+			material = folderMaterial
+		}
+		mat, ok := ms.materials[material]
+		if !ok {
+			mat = &MaterialStats{}
+			ms.materials[material] = mat
+		}
+		mat.panels += count
+		mat.area += area
+	}
+}
+
+/**
+ * writeStatisticsCSV: Сохраняет статистику в CSV-файл (UTF-8 с BOM, разделитель ";") для открытия в Excel.
+ * Одна строка на материал в месяце, итоги месяца повторяются в каждой строке.
+ */
+func writeStatisticsCSV(fullFilePath string, stats ProductionStats) error {
+	var sb strings.Builder
+	sb.WriteString(utf8BOM)
+	w := csv.NewWriter(&sb)
+	w.Comma = ';'
+	w.Write(i18n.TrAll([]string{"Месяц", "Заказов", "Проектов", "Средний срок, дней", "Материал", "Панелей", "Площадь, м²"}))
+	for _, ms := range stats.months {
+		head := []string{ms.month, strconv.Itoa(ms.orders), strconv.Itoa(ms.projects), formatDecimal(ms.averageLeadDays(), 1)}
+		materials := ms.sortedMaterials()
+		if len(materials) == 0 {
+			w.Write(append(head, "", "", ""))
+		}
+		for _, name := range materials {
+			mat := ms.materials[name]
+			w.Write(append(head, name, strconv.Itoa(mat.panels), formatDecimal(mat.area, 2)))
+		}
+	}
+	w.Write([]string{})
+	w.Write([]string{i18n.Tr("Ожидают"), strconv.Itoa(stats.pending)})
+	w.Write([]string{i18n.Tr("Требуют участия"), strconv.Itoa(stats.other)})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return fileio.CreateFile(fullFilePath, []byte(sb.String()))
+}
+
+/**
+ * writeStatisticsHTML: Сохраняет статистику в HTML-файл с таблицей по каждому месяцу.
+ */
+func writeStatisticsHTML(fullFilePath string, stats ProductionStats) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n", i18n.Language())
+	fmt.Fprintf(&sb, "<title>%s</title>\n", i18n.Tr("Статистика производства"))
+	sb.WriteString("<style>body{font-family:sans-serif}table{border-collapse:collapse;margin-bottom:1em}" +
+		"td,th{border:1px solid #999;padding:2px 8px}td.num{text-align:right}</style>\n")
+	fmt.Fprintf(&sb, "</head>\n<body>\n<h1>%s</h1>\n", i18n.Tr("Статистика производства"))
+	fmt.Fprintf(&sb, i18n.Tr("<p>Ожидают: %d, требуют участия: %d</p>\n"), stats.pending, stats.other)
+	for _, ms := range stats.months {
+		fmt.Fprintf(&sb, "<h2>%s</h2>\n", html.EscapeString(ms.month))
+		fmt.Fprintf(&sb, i18n.Tr("<p>Заказов: %d, проектов: %d, средний срок: %s дн.</p>\n"),
+			ms.orders, ms.projects, formatDecimal(ms.averageLeadDays(), 1))
+		fmt.Fprintf(&sb, "<table>\n<tr><th>%s</th><th>%s</th><th>%s</th></tr>\n", i18n.Tr("Материал"), i18n.Tr("Панелей"), i18n.Tr("Площадь, м²"))
+		for _, name := range ms.sortedMaterials() {
+			mat := ms.materials[name]
+			fmt.Fprintf(&sb, "<tr><td>%s</td><td class=\"num\">%d</td><td class=\"num\">%s</td></tr>\n",
+				html.EscapeString(name), mat.panels, formatDecimal(mat.area, 2))
+		}
+		sb.WriteString("</table>\n")
+	}
+	sb.WriteString("</body>\n</html>\n")
+	return fileio.CreateFile(fullFilePath, []byte(sb.String()))
+}
+
+/**
+ * WriteStatistics: Собирает статистику и сохраняет её в TargetDir в форматах CSV и HTML.
+ * @param settings - Настройки программы (TargetDir и имя файла статистики).
+ * @param reports - Отчёты текущего запуска.
+ */
+func WriteStatistics(settings config.Settings, reports []walker.ReportObj) {
+	if settings.FileStatistics == "" {
+		return
+	}
+	stats := collectStatistics(reports, settings)
+	baseName := filepath.Join(settings.DirTarget, settings.FileStatistics)
+	if err := writeStatisticsCSV(baseName+".csv", stats); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка сохранения статистики в CSV: %v"), err), logging.FieldPath, baseName+".csv", logging.FieldAction, "statistics", logging.FieldError, err)
+	}
+	if err := writeStatisticsHTML(baseName+".html", stats); err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка сохранения статистики в HTML: %v"), err), logging.FieldPath, baseName+".html", logging.FieldAction, "statistics", logging.FieldError, err)
+	}
+}
+
+// Форматирует число с заданным количеством знаков после запятой (десятичный разделитель - запятая)
+func formatDecimal(value float64, prec int) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', prec, 64), ".", ",", 1)
+}
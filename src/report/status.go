@@ -0,0 +1,164 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/walker"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// цвета консоли (ANSI) для дерева статусов
+const (
+	c_ANSI_GREEN  = "\033[32m"
+	c_ANSI_YELLOW = "\033[33m"
+	c_ANSI_RED    = "\033[31m"
+	c_ANSI_GRAY   = "\033[90m"
+	c_ANSI_RESET  = "\033[0m"
+)
+
+// statusProgress: Прогресс папки: готовые раскрои и детали, сделанные станками по спискам работ
+type statusProgress struct {
+	readyCuts int // готовых конечных папок (раскроев)
+	totalCuts int // всех конечных папок
+	doneParts int // сделано деталей по спискам работ папок в работе
+	planParts int // нужно деталей по этим спискам
+}
+
+// Добавляет прогресс вложенной папки
+func (progress *statusProgress) add(other statusProgress) {
+	progress.readyCuts += other.readyCuts
+	progress.totalCuts += other.totalCuts
+	progress.doneParts += other.doneParts
+	progress.planParts += other.planParts
+}
+
+/**
+ * CreateStatusTree: Формирует дерево статусов для консоли (команда status): заказчик → заказ → проект,
+ * у каждой папки - статус, дата готовности и прогресс: сколько раскроев готово и сколько деталей
+ * сделано станками по спискам работ.
+ * @param reports - Отчёты по папкам верхнего уровня с проставленными уровнями иерархии.
+ * @param startDir - Папка, от которой построены отчёты (в ней ищутся списки работ).
+ * @param settings - Настройки программы (названия уровней, профили станков).
+ * @param colored - Выделять статусы цветом (ANSI).
+ * @param all - Выводить и конечные папки раскроев (материалов), иначе они учитываются только в прогрессе.
+ * @return string - Текст дерева.
+ */
+func CreateStatusTree(reports []walker.ReportObj, startDir string, settings config.Settings, colored bool, all bool) string {
+	var lines []string
+	for _, item := range reports {
+		itemLines, _ := statusLines(item, filepath.Join(startDir, item.ItemName), "", "", &settings, colored, all)
+		lines = append(lines, itemLines...)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+/**
+ * statusLines: Формирует строки дерева для папки и, рекурсивно, вложенных папок.
+ * @param item - Отчёт о папке.
+ * @param dirPath - Полный путь к папке.
+ * @param prefix - Начало строки папки: отступ и ветка дерева.
+ * @param indent - Отступ для строк вложенных папок.
+ * @return []string - Строки дерева; пусто, если папка раскроя не выводится.
+ * @return statusProgress - Прогресс папки со всеми вложенными.
+ */
+func statusLines(item walker.ReportObj, dirPath string, prefix string, indent string, settings *config.Settings, colored bool, all bool) ([]string, statusProgress) {
+	var progress statusProgress
+	var childLines []string
+	var shown []walker.ReportObj
+	for _, inner := range item.InnerItems {
+		if all || len(inner.InnerItems) > 0 || inner.Kind != config.LevelMaterial {
+			shown = append(shown, inner)
+		}
+	}
+	for _, inner := range item.InnerItems {
+		branch, childIndent := "├── ", "│   "
+		if len(shown) > 0 && inner.ItemName == shown[len(shown)-1].ItemName {
+			branch, childIndent = "└── ", "    "
+		}
+		lines, innerProgress := statusLines(inner, filepath.Join(dirPath, inner.ItemName), indent+branch, indent+childIndent, settings, colored, all)
+		childLines = append(childLines, lines...)
+		progress.add(innerProgress)
+	}
+	if len(item.InnerItems) == 0 {
+		progress.totalCuts = 1
+		if item.Status == walker.StatusReady {
+			progress.readyCuts = 1
+		} else if item.Status == walker.StatusPending {
+			progress.doneParts, progress.planParts = readListProgress(dirPath, settings)
+		}
+		if !all && item.Kind == config.LevelMaterial {
+			return nil, progress
+		}
+	}
+
+	line := prefix
+	if item.Kind != "" {
+		line += colorize(settings.LevelTitle(item.Kind)+":", c_ANSI_GRAY, colored) + " "
+	}
+	line += item.ItemName + "  " + colorize(walker.StatusName(item.Status), statusColor(item.Status), colored)
+	if item.DateReady != "" {
+		line += " " + item.DateReady
+	}
+	var details []string
+	if len(item.InnerItems) > 0 {
+		details = append(details, fmt.Sprintf(i18n.Tr("раскроев готово %d из %d"), progress.readyCuts, progress.totalCuts))
+	}
+	if progress.planParts > 0 {
+		details = append(details, fmt.Sprintf(i18n.Tr("деталей сделано %d из %d"), progress.doneParts, progress.planParts))
+	}
+	if len(details) > 0 {
+		line += "  " + colorize("("+strings.Join(details, ", ")+")", c_ANSI_GRAY, colored)
+	}
+	return append([]string{line}, childLines...), progress
+}
+
+// Суммирует сделанные и нужные детали по спискам работ в папке (списки всех станков)
+func readListProgress(dirPath string, settings *config.Settings) (done int, planned int) {
+	dirEntries, err := fileio.ReadDir(dirPath)
+	if err != nil {
+		return 0, 0
+	}
+	for _, entry := range dirEntries {
+		machine, ok := settings.MachineByListFile(entry.Name())
+		if entry.IsDir() || !ok {
+			continue
+		}
+		data, err := fileio.ReadFile(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if listDone, listPlanned, ok := worklist.ReadProgress(machine.Format, data); ok {
+			done += listDone
+			planned += listPlanned
+		}
+	}
+	return done, planned
+}
+
+// Возвращает цвет консоли для статуса папки
+func statusColor(status string) string {
+	switch status {
+	case walker.StatusReady:
+		return c_ANSI_GREEN
+	case walker.StatusPending:
+		return c_ANSI_YELLOW
+	default:
+		return c_ANSI_RED
+	}
+}
+
+// Выделяет текст цветом консоли, если цвета включены
+func colorize(text string, color string, colored bool) string {
+	if !colored {
+		return text
+	}
+	return color + text + c_ANSI_RESET
+}
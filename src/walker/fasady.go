@@ -0,0 +1,121 @@
+package walker
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+)
+
+/**
+ * findFasadyDirs: Ищет во вложенных папках (на любой глубине) папки с фасадами.
+ * Игнорируемые папки пропускаются, внутрь найденных папок с фасадами поиск не заходит.
+ * @param currentPath - Папка, в которой лежит ready_fasady.xml.
+ * @param settings - Настройки программы.
+ * @return []string - Полные пути к папкам с фасадами, отсортированные по имени.
+ */
+func findFasadyDirs(currentPath string, settings config.Settings) []string {
+	var result []string
+	dirEntries, err := fileio.ReadDir(currentPath)
+	if err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка чтения директории %s: %v"), currentPath, err), logging.FieldPath, currentPath, logging.FieldAction, "fasady", logging.FieldError, err)
+		return nil
+	}
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			continue
+		}
+		entryFullPath := filepath.Join(currentPath, entry.Name())
+		if settings.IsIgnored(entryFullPath) {
+			continue
+		}
+		if settings.IsFasadyDir(entry.Name()) {
+			result = append(result, entryFullPath)
+		} else {
+			result = append(result, findFasadyDirs(entryFullPath, settings)...)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Проверяет, есть ли в папке файл с "ready" в имени (метка готовности или выполненный плейлист)
+func hasReadyFile(dirPath string) bool {
+	dirEntries, err := fileio.ReadDir(dirPath)
+	if err != nil {
+		return false
+	}
+	for _, entry := range dirEntries {
+		if !entry.IsDir() && !fileio.IsTempFile(entry.Name()) && strings.Contains(entry.Name(), "ready") {
+			return true
+		}
+	}
+	return false
+}
+
+// Проверяет, есть ли в папке список работы станка (list.xml или список из Machines)
+func hasListFile(dirPath string, settings config.Settings) bool {
+	dirEntries, err := fileio.ReadDir(dirPath)
+	if err != nil {
+		return false
+	}
+	for _, entry := range dirEntries {
+		if !entry.IsDir() && settings.IsListFile(entry.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * distributeReadyFasady: Раскладывает выполненный плейлист фасадов по папкам с фасадами.
+ * В каждую папку с фасадами, где ещё нет файла готовности, копируется ready_yyyymmdd.xml
+ * с датой изменения ready_fasady.xml. Повторный вызов ничего не меняет.
+ * В папку со списком работ копия не кладётся: обход сначала видит список, и папка всё равно осталась бы
+ * в работе, - вместо этого в журнал пишется предупреждение.
+ * @param readyFile - Полный путь к ready_fasady.xml.
+ * @param currentPath - Папка, в которой он лежит.
+ * @param settings - Настройки программы (шаблоны папок с фасадами).
+ * @param dryRun - true, если копии не записываются (обход без изменений, см. WalkReadOnly).
+ * @return bool - true, если найдена хотя бы одна папка с фасадами и все они получили файл готовности.
+ * @return error - Ошибка чтения ready_fasady.xml или записи копии.
+ */
+func distributeReadyFasady(readyFile string, currentPath string, settings config.Settings, dryRun bool) (bool, error) {
+	fasadyDirs := findFasadyDirs(currentPath, settings)
+	if len(fasadyDirs) == 0 {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Путь: %s. Не найдены папки с фасадами (шаблоны: %v)"), currentPath, settings.FasadyPatterns), logging.FieldPath, currentPath, logging.FieldAction, "fasady")
+		return false, nil
+	}
+	info, err := fileio.Stat(readyFile)
+	if err != nil {
+		return false, err
+	}
+	data, err := fileio.ReadFile(readyFile)
+	if err != nil {
+		return false, err
+	}
+	targetName := "ready_" + info.ModTime().Format("20060102") + ".xml"
+	for _, dir := range fasadyDirs {
+		if hasReadyFile(dir) {
+			continue
+		}
+		if hasListFile(dir, settings) {
+			logging.Warn(fmt.Sprintf(i18n.Tr("В папке с фасадами %s есть список работ, файл %s в неё не скопирован"), dir, filepath.Base(readyFile)),
+				logging.FieldPath, dir, logging.FieldAction, "fasady-ready")
+			continue
+		}
+		if dryRun {
+			continue
+		}
+		if err := fileio.CreateFile(filepath.Join(dir, targetName), data); err != nil {
+			return false, err
+		}
+		logging.Info(fmt.Sprintf(i18n.Tr("Файл %s скопирован в %s как %s"), filepath.Base(readyFile), dir, targetName), logging.FieldPath, dir, logging.FieldAction, "fasady-ready")
+	}
+	return true, nil
+}
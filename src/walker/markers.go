@@ -0,0 +1,254 @@
+package walker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// Версия формата XML-отчётов и меток order_ready; файлы без атрибута Version считаются версией 1.
+// С версии 3 статусы хранятся кодами (c_ST_*), а не русскими названиями
+const c_REPORT_VERSION = 3
+
+// Программа, указываемая в атрибуте Generator
+const ReportGenerator = "ListMaker"
+
+/**
+ * reportChecksum: Вычисляет контрольную сумму (SHA-256) содержимого отчёта.
+ * Сериализация без отступов, поэтому сумма не зависит от форматирования файла.
+ */
+func reportChecksum(list XReportItemList) (string, error) {
+	data, err := xml.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+/**
+ * ReadReportFile: Строго читает XML-отчёт или метку о выполнении заказа.
+ * Проверяет версию формата, контрольную сумму и каждую запись отчёта.
+ * Файлы версии 1 (без атрибутов Version, Checksum и Generator) читаются без проверки суммы и приводятся
+ * к текущему виду; файл без Version, но с Checksum или Generator считается повреждённым.
+ * @param fullFileName - Полный путь к файлу.
+ * @return []ReportObj - Отчёты верхнего уровня.
+ * @return string - Контрольная сумма файла; пустая у файлов старого формата, которые следует перезаписать.
+ * @return error - Описание ошибки, если файл повреждён или не читается.
+ */
+func ReadReportFile(fullFileName string) ([]ReportObj, string, error) {
+	myFileBytes, err := fileio.ReadFile(fullFileName)
+	if err != nil {
+		return nil, "", fmt.Errorf(i18n.Tr("не удалось прочитать файл отчёта %s: %w"), fullFileName, err)
+	}
+	return parseReportData(myFileBytes, fullFileName)
+}
+
+/**
+ * parseReportData: Строго разбирает содержимое XML-отчёта (см. ReadReportFile).
+ * @param myFileBytes - Содержимое файла.
+ * @param fullFileName - Имя файла для сообщений об ошибках.
+ */
+func parseReportData(myFileBytes []byte, fullFileName string) ([]ReportObj, string, error) {
+	var err error
+	var myRepXML XReportHead
+	decoded, _ := fileio.DecodeXML(myFileBytes)
+	if err = xml.Unmarshal(decoded, &myRepXML); err != nil {
+		return nil, "", fmt.Errorf(i18n.Tr("не удалось разобрать XML из файла отчёта %s: %w"), fullFileName, err)
+	}
+
+	legacy := myRepXML.Version == ""
+	version := 1
+	if legacy {
+		// у файлов версии 1 нет ни суммы, ни Generator: файл с ними, но без версии, изменён вручную
+		if myRepXML.Checksum != "" || myRepXML.Generator != "" {
+			return nil, "", fmt.Errorf(i18n.Tr("файл %s изменён: есть Checksum или Generator, но нет Version"), fullFileName)
+		}
+	} else {
+		var errVersion error
+		version, errVersion = strconv.Atoi(myRepXML.Version)
+		if errVersion != nil || version < 2 || version > c_REPORT_VERSION {
+			return nil, "", fmt.Errorf(i18n.Tr("неподдерживаемая версия формата %q в файле %s"), myRepXML.Version, fullFileName)
+		}
+		checksum, errSum := reportChecksum(myRepXML.ReportItemList)
+		if errSum != nil {
+			return nil, "", fmt.Errorf(i18n.Tr("не удалось вычислить контрольную сумму файла %s: %w"), fullFileName, errSum)
+		}
+		if checksum != myRepXML.Checksum {
+			return nil, "", fmt.Errorf(i18n.Tr("контрольная сумма файла %s не совпадает, файл изменён или повреждён"), fullFileName)
+		}
+	}
+	for i := range myRepXML.ReportItemList.ReportItem {
+		entry := &myRepXML.ReportItemList.ReportItem[i]
+		if version < 3 {
+			// контрольная сумма уже проверена, названия статусов можно заменить кодами
+			entry.convertLegacyStatus()
+		}
+		if err := entry.validate(""); err != nil {
+			return nil, "", fmt.Errorf(i18n.Tr("файл отчёта %s: %w"), fullFileName, err)
+		}
+	}
+
+	reports := getReportObjects(myRepXML)
+	if legacy {
+		for i := range reports {
+			reports[i].unwrapLegacyNesting()
+		}
+	}
+	return reports, myRepXML.Checksum, nil
+}
+
+/**
+ * ReadOrderMarker: Читает метку order_ready_yyyymmdd.xml, в которой должен быть ровно один отчёт.
+ * @return ReportObj - Отчёт о папке; checksum пуст, если метка старого формата и её следует перезаписать.
+ * @return error - Описание ошибки, если метка повреждена.
+ */
+func ReadOrderMarker(fullFileName string) (ReportObj, error) {
+	myFileBytes, err := fileio.ReadFile(fullFileName)
+	if err != nil {
+		return ReportObj{}, fmt.Errorf(i18n.Tr("не удалось прочитать метку %s: %w"), fullFileName, err)
+	}
+	return ParseOrderMarker(myFileBytes, fullFileName)
+}
+
+// Разбирает содержимое метки order_ready (см. ReadOrderMarker)
+func ParseOrderMarker(myFileBytes []byte, fullFileName string) (ReportObj, error) {
+	reports, checksum, err := parseReportData(myFileBytes, fullFileName)
+	if err != nil {
+		return ReportObj{}, err
+	}
+	if len(reports) != 1 {
+		return ReportObj{}, fmt.Errorf(i18n.Tr("метка %s должна содержать один отчёт, найдено: %d"), fullFileName, len(reports))
+	}
+	reports[0].Checksum = checksum
+	return reports[0], nil
+}
+
+/**
+ * validate: Проверяет запись отчёта и все вложенные записи.
+ * @param parent - Путь вышестоящих записей для сообщения об ошибке.
+ */
+func (item *XReportItem) validate(parent string) error {
+	path := item.ItemName
+	if parent != "" {
+		path = parent + "/" + item.ItemName
+	}
+	if strings.TrimSpace(item.ItemName) == "" {
+		return fmt.Errorf(i18n.Tr("пустое имя папки в записи %q"), parent+"/")
+	}
+	switch item.Status {
+	case StatusReady:
+		if _, err := time.Parse(time.DateOnly, item.DateReady); err != nil {
+			return fmt.Errorf(i18n.Tr("некорректная дата готовности %q у папки %s"), item.DateReady, path)
+		}
+	case StatusPending, StatusOther:
+	default:
+		return fmt.Errorf(i18n.Tr("неизвестный статус %q у папки %s"), item.Status, path)
+	}
+	if item.Level < 0 {
+		return fmt.Errorf(i18n.Tr("отрицательный уровень %d у папки %s"), item.Level, path)
+	}
+	for _, entry := range item.ReportItemList.ReportItem {
+		if err := entry.validate(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * convertLegacyStatus: Заменяет русские названия статусов из файлов версий 1 и 2 кодами (c_ST_*).
+ */
+func (item *XReportItem) convertLegacyStatus() {
+	for code, name := range statusNames {
+		if item.Status == name {
+			item.Status = code
+			break
+		}
+	}
+	for i := range item.ReportItemList.ReportItem {
+		item.ReportItemList.ReportItem[i].convertLegacyStatus()
+	}
+}
+
+/**
+ * unwrapLegacyNesting: Убирает двойную вложенность из отчётов версии 1.
+ * Раньше при чтении метки содержимое файла (отчёт о самой папке) становилось вложенным отчётом,
+ * и в метках вышестоящих папок папка оказывалась вложенной сама в себя.
+ * Единственная вложенная запись с тем же именем и статусом считается таким повтором.
+ */
+func (item *ReportObj) unwrapLegacyNesting() {
+	for len(item.InnerItems) == 1 && item.InnerItems[0].ItemName == item.ItemName &&
+		item.InnerItems[0].Status == item.Status && item.InnerItems[0].Level < item.Level {
+		inner := item.InnerItems[0]
+		item.Level = inner.Level
+		item.InnerItems = inner.InnerItems
+		if item.DateReady == "" {
+			item.DateReady = inner.DateReady
+		}
+	}
+	for i := range item.InnerItems {
+		item.InnerItems[i].unwrapLegacyNesting()
+	}
+}
+
+/**
+ * verifyChildMarkers: Сверяет контрольные суммы, записанные в отчёте, с метками во вложенных папках.
+ * Проверка рекурсивная: каждая найденная метка проверяется так же строго, как и сама метка папки.
+ * @param dirPath - Папка, которой принадлежит отчёт.
+ * @param item - Отчёт, прочитанный из метки этой папки.
+ * @return error - Описание первого найденного расхождения.
+ */
+func verifyChildMarkers(dirPath string, item ReportObj) error {
+	for _, inner := range item.InnerItems {
+		if inner.Checksum == "" {
+			continue
+		}
+		childDir := filepath.Join(dirPath, inner.ItemName)
+		markerPath := FindOrderMarker(childDir)
+		if markerPath == "" {
+			return fmt.Errorf(i18n.Tr("в папке %s нет метки о выполнении, указанной в отчёте"), childDir)
+		}
+		child, err := ReadOrderMarker(markerPath)
+		if err != nil {
+			return err
+		}
+		if child.Checksum != inner.Checksum {
+			return fmt.Errorf(i18n.Tr("метка %s не совпадает с отчётом папки %s"), markerPath, dirPath)
+		}
+		if err := verifyChildMarkers(childDir, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * FindOrderMarker: Ищет метку order_ready_yyyymmdd.xml непосредственно в папке заказа.
+ * @return string - Полный путь к метке или пустая строка, если метки нет.
+ */
+func FindOrderMarker(orderDir string) string {
+	entries, err := fileio.ReadDir(orderDir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && IsOrderMarkerName(entry.Name()) {
+			return filepath.Join(orderDir, entry.Name())
+		}
+	}
+	return ""
+}
+
+// Проверяет, является ли имя файла именем метки order_ready_yyyymmdd.xml
+func IsOrderMarkerName(name string) bool {
+	return strings.HasPrefix(name, "order_ready_") && fileio.GetExtension(name) == "xml"
+}
@@ -0,0 +1,95 @@
+package walker
+
+import (
+	"path/filepath"
+
+	"github.com/ProOwler/ListMaker/config"
+)
+
+/**
+ * AssignLevels: Проставляет вид уровня отчёту о папке и всем вложенным отчётам.
+ * @param depth - Глубина папки от стартовой папки.
+ * @param settings - Настройки с описанием иерархии.
+ */
+func (item *ReportObj) AssignLevels(depth int, settings *config.Settings) {
+	item.Kind = settings.LevelKind(item.ItemName, depth, len(item.InnerItems) == 0)
+	for i := range item.InnerItems {
+		item.InnerItems[i].AssignLevels(depth+1, settings)
+	}
+}
+
+/**
+ * CountKind: Подсчитывает вложенные отчёты (на любой глубине) указанного вида.
+ */
+func (item *ReportObj) CountKind(kind string) int {
+	count := 0
+	for _, inner := range item.InnerItems {
+		if inner.Kind == kind {
+			count++
+		}
+		count += inner.CountKind(kind)
+	}
+	return count
+}
+
+// Заказ вместе с путём к нему от стартовой папки
+type OrderRef struct {
+	Parents []ReportObj // Вышестоящие папки, начиная с верхнего уровня
+	Item    ReportObj
+}
+
+// Имена папок от верхнего уровня до заказа включительно
+func (ref OrderRef) Names() []string {
+	var result []string
+	for _, parent := range ref.Parents {
+		result = append(result, parent.ItemName)
+	}
+	return append(result, ref.Item.ItemName)
+}
+
+// Относительный путь к заказу от стартовой папки
+func (ref OrderRef) RelPath() string {
+	return filepath.Join(ref.Names()...)
+}
+
+// Имя ближайшей вышестоящей папки указанного уровня или пустая строка
+func (ref OrderRef) AncestorName(kind string) string {
+	for i := len(ref.Parents) - 1; i >= 0; i-- {
+		if ref.Parents[i].Kind == kind {
+			return ref.Parents[i].ItemName
+		}
+	}
+	return ""
+}
+
+/**
+ * CollectOrders: Находит в дереве отчётов заказы (уровень order).
+ * Ветки, в которых уровень заказа не встретился, возвращаются своей верхней папкой,
+ * чтобы ни одна папка не выпала из отчёта.
+ * @param reports - Отчёты верхнего уровня.
+ * @return []OrderRef - Заказы с путями.
+ */
+func CollectOrders(reports []ReportObj) []OrderRef {
+	var result []OrderRef
+	var walk func(item ReportObj, parents []ReportObj) bool
+	walk = func(item ReportObj, parents []ReportObj) bool {
+		if item.Kind == config.LevelOrder {
+			result = append(result, OrderRef{Parents: parents, Item: item})
+			return true
+		}
+		found := false
+		path := append(append([]ReportObj{}, parents...), item)
+		for _, inner := range item.InnerItems {
+			if walk(inner, path) {
+				found = true
+			}
+		}
+		return found
+	}
+	for _, rep := range reports {
+		if !walk(rep, nil) {
+			result = append(result, OrderRef{Item: rep})
+		}
+	}
+	return result
+}
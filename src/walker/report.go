@@ -0,0 +1,173 @@
+package walker
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+)
+
+// XML-представление отчёта
+type XReportHead struct {
+	XMLName        xml.Name        `xml:"Root"`
+	Version        string          `xml:"Version,attr,omitempty"`   // Версия формата, у старых файлов отсутствует
+	Generator      string          `xml:"Generator,attr,omitempty"` // Программа, создавшая файл
+	Created        string          `xml:"Created,attr,omitempty"`   // Дата и время создания файла
+	Checksum       string          `xml:"Checksum,attr,omitempty"`  // SHA-256 содержимого ReportItemList
+	ReportItemList XReportItemList `xml:"ReportItemList"`
+}
+
+type XReportItemList struct {
+	ReportItem []XReportItem `xml:"ReportItem"`
+}
+
+type XReportItem struct {
+	ItemName       string          `xml:"ItemName,attr"`
+	Status         string          `xml:"Status,attr"`
+	DateReady      string          `xml:"DateReady,attr"`
+	Level          int             `xml:"Level,attr"`
+	MarkerChecksum string          `xml:"MarkerChecksum,attr,omitempty"` // Контрольная сумма метки во вложенной папке
+	ReportItemList XReportItemList `xml:"ReportItemList,omitempty"`
+}
+
+// GO-представление отчёта
+type ReportObj struct {
+	ItemName   string
+	Status     string
+	DateReady  string
+	Level      int
+	InnerItems []ReportObj
+	Checksum   string // Контрольная сумма метки order_ready, из которой прочитан или в которую записан отчёт
+	Kind       string // Уровень иерархии (config.Level*), проставляется после обхода по настройкам
+}
+
+/**
+ * WriteReportToFile: Сохраняет отчёт о папке в метку о выполнении заказа
+ * и запоминает контрольную сумму метки для отчёта вышестоящей папки.
+ * @param fullFilePath - Полный путь к файлу метки.
+ * @return error - Ошибка сериализации или записи.
+ */
+func (item *ReportObj) WriteReportToFile(fullFilePath string) error {
+	var objects []ReportObj
+	objects = append(objects, *item)
+	checksum, err := WriteReportsToFile(fullFilePath, objects)
+	if err == nil {
+		item.Checksum = checksum
+	}
+	return err
+}
+
+/**
+ * WriteReportsToFile: Сохраняет список отчётов в XML-файл в формате метки о выполнении заказа.
+ * @param fullFilePath - Полный путь к файлу.
+ * @param objects - Отчёты верхнего уровня.
+ * @return string - Контрольная сумма содержимого.
+ * @return error - Ошибка сериализации или записи.
+ */
+func WriteReportsToFile(fullFilePath string, objects []ReportObj) (string, error) {
+	xmlReport := getReportXML(objects)
+	// собственная контрольная сумма хранится в заголовке, у элементов верхнего уровня её нет
+	for i := range xmlReport.ReportItemList.ReportItem {
+		xmlReport.ReportItemList.ReportItem[i].MarkerChecksum = ""
+	}
+	checksum, err := reportChecksum(xmlReport.ReportItemList)
+	if err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка при сериализации XML: %v"), err), logging.FieldPath, fullFilePath, logging.FieldAction, "write-report", logging.FieldError, err)
+		return "", err
+	}
+	xmlReport.Version = strconv.Itoa(c_REPORT_VERSION)
+	xmlReport.Generator = ReportGenerator
+	xmlReport.Created = time.Now().Format(time.DateTime)
+	xmlReport.Checksum = checksum
+
+	myHeader := `<?xml version="1.0" encoding="utf-8" ?>` + "\n"
+	xmlReportString := ""
+	xmlReportBytes, errMarshal := xml.MarshalIndent(xmlReport, "", "	") // Используем табуляцию для отступов
+	if errMarshal != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка при сериализации XML: %v"), errMarshal), logging.FieldPath, fullFilePath, logging.FieldAction, "write-report", logging.FieldError, errMarshal)
+		return "", errMarshal
+	}
+
+	xmlReportString = myHeader + string(xmlReportBytes)
+	return checksum, fileio.CreateVerifiedFile(fullFilePath, []byte(xmlReportString), func(data []byte) error {
+		_, written, err := parseReportData(data, fullFilePath)
+		if err == nil && written != checksum {
+			err = fmt.Errorf(i18n.Tr("контрольная сумма записанного файла не совпадает"))
+		}
+		return err
+	})
+}
+
+func getReportXML(itemObj []ReportObj) XReportHead {
+	var result = XReportHead{}
+	for _, entry := range itemObj {
+		result.ReportItemList.ReportItem = append(result.ReportItemList.ReportItem, entry.convertReportItemToXML())
+	}
+
+	return result
+}
+
+func (item *ReportObj) convertReportItemToXML() XReportItem {
+	var result = XReportItem{
+		ItemName:  item.ItemName,
+		Level:     item.Level,
+		DateReady: item.DateReady,
+		Status:    item.Status,
+		// для вложенных папок - сумма их собственной метки
+		MarkerChecksum: item.Checksum,
+	}
+	for _, entry := range item.InnerItems {
+		result.ReportItemList.ReportItem = append(result.ReportItemList.ReportItem, entry.convertReportItemToXML())
+	}
+	return result
+}
+
+func getReportObjects(itemX XReportHead) []ReportObj {
+	var result = []ReportObj{}
+	for _, entry := range itemX.ReportItemList.ReportItem {
+		result = append(result, entry.convertReportItemToObj())
+	}
+	return result
+}
+
+func (item *XReportItem) convertReportItemToObj() ReportObj {
+	var result = ReportObj{
+		ItemName:  item.ItemName,
+		Level:     item.Level,
+		DateReady: item.DateReady,
+		Status:    item.Status,
+		Checksum:  item.MarkerChecksum,
+	}
+	for _, entry := range item.ReportItemList.ReportItem {
+		result.InnerItems = append(result.InnerItems, entry.convertReportItemToObj())
+	}
+	return result
+}
+
+/**
+ * FindSavedReports: Возвращает сохранённые XML-отчёты о работе из TargetDir, от старых к новым.
+ * Имена отчётов начинаются с даты и времени запуска, поэтому сортировка по имени хронологическая.
+ */
+func FindSavedReports(settings config.Settings) []string {
+	pattern := "*_" + strings.TrimSuffix(settings.FileReport, filepath.Ext(settings.FileReport)) + ".xml"
+	files, err := filepath.Glob(filepath.Join(settings.DirTarget, pattern))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Читает сохранённый отчёт о работе
+func ReadSavedReport(fullFileName string) ([]ReportObj, error) {
+	reports, _, err := ReadReportFile(fullFileName)
+	return reports, err
+}
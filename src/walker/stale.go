@@ -0,0 +1,194 @@
+package walker
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// ListCheck: Результат сравнения списка работ с файлами-заданиями его папки
+type ListCheck struct {
+	ListPath string                // Полный путь к файлу списка
+	Machine  config.MachineProfile // Профиль станка, которому принадлежит список
+	JobFiles []string              // Полные пути к файлам-заданиям, которые сейчас лежат в папке
+	Added    []string              // Имена файлов-заданий, которых нет в списке
+	Removed  []string              // Имена файлов из списка, которых нет в папке
+	Changed  []string              // Имена файлов-заданий, изменённых после записи списка
+}
+
+/**
+ * CheckListFile: Сравнивает список работ с файлами-заданиями в его папке.
+ * Файлы сравниваются по именам: список составляется для одной папки, а пути в нём
+ * могут быть записаны так, как их видит станок (см. ListPaths).
+ * @param listPath - Полный путь к файлу списка.
+ * @param settings - Настройки программы (профили станков).
+ * @return ListCheck - Результат сравнения.
+ * @return error - Ошибка чтения папки или разбора списка.
+ */
+func CheckListFile(listPath string, settings config.Settings) (ListCheck, error) {
+	currentPath, listName := filepath.Dir(listPath), filepath.Base(listPath)
+	check := ListCheck{ListPath: listPath}
+	dirEntries, err := fileio.ReadDir(currentPath)
+	if err != nil {
+		return check, err
+	}
+	var shortFileNames []string
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			shortFileNames = append(shortFileNames, entry.Name())
+		}
+	}
+	// профиль папки, если его список - этот файл, иначе профиль по имени файла списка
+	check.Machine = settings.MachineFor(filepath.Base(currentPath), shortFileNames)
+	if !strings.EqualFold(check.Machine.ListFile, listName) {
+		check.Machine, _ = settings.MachineByListFile(listName)
+	}
+	listInfo, err := fileio.Stat(listPath)
+	if err != nil {
+		return check, err
+	}
+	data, err := fileio.ReadFile(listPath)
+	if err != nil {
+		return check, err
+	}
+	listedPaths, err := worklist.ReadFilePaths(check.Machine.Format, data)
+	if err != nil {
+		return check, fmt.Errorf(i18n.Tr("не удалось разобрать список %s: %w"), listPath, err)
+	}
+	listed := make(map[string]string)
+	for _, listedPath := range listedPaths {
+		resolved := check.Machine.ListPaths.Resolve(listedPath, currentPath)
+		name := path.Base(strings.ReplaceAll(resolved, `\`, "/"))
+		listed[strings.ToLower(name)] = name
+	}
+	sort.Strings(shortFileNames)
+	for _, name := range shortFileNames {
+		if !check.Machine.IsJobFile(name) {
+			continue
+		}
+		fullName := filepath.Join(currentPath, name)
+		check.JobFiles = append(check.JobFiles, fullName)
+		if _, ok := listed[strings.ToLower(name)]; !ok {
+			check.Added = append(check.Added, name)
+			continue
+		}
+		delete(listed, strings.ToLower(name))
+		if info, err := fileio.Stat(fullName); err == nil && info.ModTime().After(listInfo.ModTime()) {
+			check.Changed = append(check.Changed, name)
+		}
+	}
+	for _, name := range listed {
+		check.Removed = append(check.Removed, name)
+	}
+	sort.Strings(check.Removed)
+	return check, nil
+}
+
+/**
+ * IsStale: Возвращает true, если список работ не совпадает с файлами-заданиями папки.
+ */
+func (check ListCheck) IsStale() bool {
+	return len(check.Added) > 0 || len(check.Removed) > 0 || len(check.Changed) > 0
+}
+
+/**
+ * String: Описание расхождений для журнала: какие файлы добавлены, удалены и изменены.
+ */
+func (check ListCheck) String() string {
+	var parts []string
+	if len(check.Added) > 0 {
+		parts = append(parts, fmt.Sprintf(i18n.Tr("добавлены: %s"), strings.Join(check.Added, ", ")))
+	}
+	if len(check.Removed) > 0 {
+		parts = append(parts, fmt.Sprintf(i18n.Tr("удалены: %s"), strings.Join(check.Removed, ", ")))
+	}
+	if len(check.Changed) > 0 {
+		parts = append(parts, fmt.Sprintf(i18n.Tr("изменены: %s"), strings.Join(check.Changed, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+/**
+ * RefreshList: Пересоздаёт список работ по текущим файлам-заданиям папки.
+ * Сделанное станком количество (Count) сохраняется для деталей, файлы которых не изменились.
+ * @param check - Результат CheckListFile.
+ * @param settings - Настройки программы (правила имён файлов).
+ * @return error - Ошибка чтения старого списка или записи нового; в папке нет файлов-заданий.
+ */
+func RefreshList(check ListCheck, settings config.Settings) error {
+	if len(check.JobFiles) == 0 {
+		return fmt.Errorf(i18n.Tr("в папке %s нет файлов-заданий для списка %s"), filepath.Dir(check.ListPath), filepath.Base(check.ListPath))
+	}
+	data, err := fileio.ReadFile(check.ListPath)
+	if err != nil {
+		return err
+	}
+	counts, err := worklist.ReadCounts(check.Machine.Format, data)
+	if err != nil {
+		return err
+	}
+	for _, name := range check.Changed {
+		delete(counts, strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+	// новые и изменённые XML-файлы деталей дополняются размерами в именах панелей, как при создании списка
+	for _, name := range append(append([]string{}, check.Added...), check.Changed...) {
+		if strings.ToLower(fileio.GetExtension(name)) == "xml" {
+			panel.UpdateFileWithXML(filepath.Join(filepath.Dir(check.ListPath), name))
+		}
+	}
+	outputString := worklist.Regenerate(check.Machine.Format, check.JobFiles, check.Machine.FileTypes, check.Machine.ListPaths, counts, settings.NameGrammar)
+	return fileio.CreateVerifiedFile(check.ListPath, []byte(outputString), worklist.VerifyFormat(check.Machine.Format))
+}
+
+/**
+ * checkStaleList: Проверяет список работ папки при обходе и поступает с устаревшим списком по настройке StaleLists.
+ * @param listPath - Полный путь к файлу списка.
+ * @param settings - Настройки программы.
+ * @param dryRun - true, если список не пересоздаётся (обход без изменений, см. WalkReadOnly).
+ * @return string - StatusOther, если папка требует участия пользователя, иначе StatusPending.
+ */
+func checkStaleList(listPath string, settings config.Settings, dryRun bool) string {
+	check, err := CheckListFile(listPath, settings)
+	if err != nil {
+		logging.Warn(fmt.Sprintf(i18n.Tr("Не удалось сверить список заданий %s с файлами папки: %v"), listPath, err),
+			logging.FieldPath, listPath, logging.FieldAction, "check-list", logging.FieldError, err)
+		return StatusPending
+	}
+	if !check.IsStale() {
+		return StatusPending
+	}
+	switch settings.StaleLists {
+	case config.StaleListsWarn:
+		logging.Warn(fmt.Sprintf(i18n.Tr("Список заданий %s не совпадает с файлами папки (%s)"), listPath, check),
+			logging.FieldPath, listPath, logging.FieldAction, "check-list")
+	case config.StaleListsRegenerate:
+		if dryRun {
+			// пересоздать список без файлов-заданий не удастся, папка потребует участия
+			if len(check.JobFiles) == 0 {
+				return StatusOther
+			}
+			break
+		}
+		if err := RefreshList(check, settings); err != nil {
+			logging.Error(fmt.Sprintf(i18n.Tr("Ошибка обновления списка заданий %s: %v"), listPath, err),
+				logging.FieldPath, listPath, logging.FieldAction, "refresh-list", logging.FieldError, err)
+			return StatusOther
+		}
+		logging.Info(fmt.Sprintf(i18n.Tr("Список заданий %s обновлён (%s)"), listPath, check),
+			logging.FieldPath, listPath, logging.FieldAction, "refresh-list", "machine", check.Machine.Name)
+	default:
+		logging.Warn(fmt.Sprintf(i18n.Tr("Требуется участие пользователя: список заданий %s не совпадает с файлами папки (%s), обновите его командой refresh-lists"), listPath, check),
+			logging.FieldPath, listPath, logging.FieldAction, "check-list")
+		return StatusOther
+	}
+	return StatusPending
+}
@@ -0,0 +1,300 @@
+// Пакет walker - обход папок заказов: классификация папок (заказчик, заказ, проект, раскрой),
+// формирование list.xml, метки order_ready и дерево отчётов ReportObj.
+package walker
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// статусы обработки папок; в файлах хранятся эти коды, в отчётах выводятся названия (StatusName)
+const (
+	StatusOther   string = "other"
+	StatusReady   string = "ready"
+	StatusPending string = "pending"
+)
+
+// названия статусов для вывода; в файлах версии 2 и ранее статусы записаны этими названиями
+var statusNames = map[string]string{
+	StatusOther:   "Иное",
+	StatusReady:   "Готов",
+	StatusPending: "Ожидает",
+}
+
+/**
+ * StatusName: Возвращает название статуса на выбранном языке.
+ */
+func StatusName(status string) string {
+	if name, ok := statusNames[status]; ok {
+		return i18n.Tr(name)
+	}
+	return status
+}
+
+/**
+ * Walk: Рекурсивно обходит директории, обрабатывает файлы и создает list.xml.
+ * @param currentPath - Текущая директория для обхода.
+ * @param settings - Настройки программы (для доступа к списку игнорирования).
+ */
+func Walk(currentPath string, settings config.Settings) ReportObj {
+	return walk(currentPath, settings, false)
+}
+
+/**
+ * WalkReadOnly: Обходит директории так же, как Walk, но ничего не меняет: списки работ, метки order_ready
+ * и копии ready_fasady.xml не создаются, файлы деталей не дополняются, устаревшие списки не пересоздаются.
+ * Папка, для которой Walk создал бы список работ, получает статус ОЖИДАЕТ; заказ, для которого Walk
+ * записал бы метку, - ГОТОВ. В отличие от Walk, обход не прерывается на папке со статусом ИНОЕ:
+ * в отчёт попадают все вложенные папки (команда status).
+ * @param currentPath - Текущая директория для обхода.
+ * @param settings - Настройки программы.
+ */
+func WalkReadOnly(currentPath string, settings config.Settings) ReportObj {
+	return walk(currentPath, settings, true)
+}
+
+// Обход для Walk и WalkReadOnly; dryRun - ничего не записывать
+func walk(currentPath string, settings config.Settings, dryRun bool) ReportObj {
+	// Получаем список содержимого текущей директории
+	currentPathShort := filepath.Base(currentPath)
+	logging.Debug(fmt.Sprintf(i18n.Tr("Обработка папки %s"), currentPath), logging.FieldPath, currentPath, logging.FieldAction, "walk")
+	dirEntries, err := fileio.ReadDir(currentPath)
+	if err != nil {
+		logging.Error(fmt.Sprintf(i18n.Tr("Ошибка чтения директории %s: %v"), currentPath, err), logging.FieldPath, currentPath, logging.FieldAction, "walk", logging.FieldError, err)
+		return ReportObj{}
+	}
+
+	// алг - всё содержимое осматриваемой папки разделить на 2 перечня - [подпапки, файлы]
+	var dirEntriesFileNames, dirEntriesDirNames, fullnamesToProceed, shortFileNames []string
+	for _, entry := range dirEntries {
+		entryFullPath := filepath.Join(currentPath, entry.Name())
+		if entry.IsDir() {
+			// Проверяем, нужно ли игнорировать эту директорию
+			if settings.IsIgnored(entryFullPath) {
+				continue // Пропускаем игнорируемую папку
+			}
+			dirEntriesDirNames = append(dirEntriesDirNames, entryFullPath)
+		} else if !fileio.IsTempFile(entry.Name()) {
+			// временные файлы прерванной записи (.order_ready_….xml.….tmp) не должны приниматься за метки
+			dirEntriesFileNames = append(dirEntriesFileNames, entryFullPath)
+			shortFileNames = append(shortFileNames, entry.Name())
+		}
+	}
+
+	if len(dirEntriesFileNames) > 0 {
+		sort.Strings(dirEntriesFileNames)
+		// алг - если есть файл "плейлист" (list.xml или список другого станка),
+		for _, shortFileName := range shortFileNames {
+			if settings.IsListFile(shortFileName) {
+				//fmt.Println("Есть файл-список заданий")
+				// список сверяется с файлами-заданиями папки: их могли добавить, удалить или заменить
+				return ReportObj{
+					ItemName:  currentPathShort,
+					Level:     0,
+					DateReady: "",
+					Status:    checkStaleList(filepath.Join(currentPath, shortFileName), settings, dryRun),
+				}
+			}
+		}
+		// станок, для которого составляется список работ этой папки
+		machine := settings.MachineFor(currentPathShort, shortFileNames)
+		for _, fileName := range dirEntriesFileNames {
+			if strings.Contains(filepath.Base(fileName), "ready") {
+				// алг - если есть файл "плейлист фасадов" выполненный (ready_fasady.xml),
+				if strings.Contains(filepath.Base(fileName), "fasady") {
+					// разложить его по папкам с фасадами и продолжить обход как обычно
+					if distributed, err := distributeReadyFasady(fileName, currentPath, settings, dryRun); err == nil && distributed {
+						continue
+					} else if err != nil {
+						logging.Error(fmt.Sprintf(i18n.Tr("Ошибка раскладки %s: %v"), fileName, err), logging.FieldPath, fileName, logging.FieldAction, "fasady", logging.FieldError, err)
+					}
+					logging.Warn(fmt.Sprintf(i18n.Tr("Путь: %s. Переместите файл ready_fasady.xml в папки с фасадами"), currentPath), logging.FieldPath, currentPath, logging.FieldAction, "fasady")
+					return ReportObj{
+						ItemName:  currentPathShort,
+						Level:     0,
+						DateReady: "",
+						Status:    StatusPending,
+					}
+				}
+				// алг - если есть файл-метка-отчёт order_ready_yyyymmdd.xml,
+				if IsOrderMarkerName(filepath.Base(fileName)) {
+					marker, errMarker := ReadOrderMarker(fileName)
+					if errMarker == nil {
+						errMarker = verifyChildMarkers(currentPath, marker)
+					}
+					if errMarker != nil {
+						// повреждённая метка не должна давать пустую или ложную запись в отчёте
+						logging.Warn(fmt.Sprintf(i18n.Tr("Требуется участие пользователя: повреждена метка о выполнении заказа: %v"), errMarker),
+							logging.FieldPath, fileName, logging.FieldAction, "read-marker", logging.FieldError, errMarker)
+						return ReportObj{
+							ItemName:  currentPathShort,
+							Level:     0,
+							DateReady: "",
+							Status:    StatusOther,
+						}
+					}
+					if marker.Checksum == "" && !dryRun {
+						// метка старого формата переписывается в текущем
+						if marker.WriteReportToFile(fileName) == nil {
+							logging.Info(fmt.Sprintf(i18n.Tr("Метка %s переведена в формат версии %d"), fileName, c_REPORT_VERSION), logging.FieldPath, fileName, logging.FieldAction, "migrate-marker")
+						}
+					}
+					marker.ItemName = currentPathShort
+					if dateString, errDate := settings.NameGrammar.GetReadyDate(filepath.Base(fileName)); errDate == nil {
+						marker.DateReady = dateString
+						marker.Status = StatusReady
+						return marker
+					} else {
+						logging.Warn(fmt.Sprintf(i18n.Tr("Ошибка извлечения даты из имени файла %s: %v"), fileName, errDate), logging.FieldPath, fileName, logging.FieldAction, "read-marker", logging.FieldError, errDate)
+						return ReportObj{
+							ItemName:   currentPathShort,
+							Level:      marker.Level,
+							DateReady:  dateString,
+							Status:     StatusPending,
+							InnerItems: marker.InnerItems,
+						}
+					}
+				}
+				// алг - если есть выполненный файл "плейлист" (ready_yyyymmdd.xml),
+				if dateString, errDate := settings.NameGrammar.GetReadyDate(filepath.Base(fileName)); errDate == nil {
+					return ReportObj{
+						ItemName:  currentPathShort,
+						Level:     0,
+						DateReady: dateString,
+						Status:    StatusReady,
+					}
+				} else {
+					logging.Warn(fmt.Sprintf(i18n.Tr("Ошибка извлечения даты из имени файла %s: %v"), fileName, errDate), logging.FieldPath, fileName, logging.FieldAction, "read-ready", logging.FieldError, errDate)
+					return ReportObj{
+						ItemName:  currentPathShort,
+						Level:     0,
+						DateReady: dateString,
+						Status:    StatusPending,
+					}
+				}
+			}
+			// алг - если есть подходящие для обработки файлы-задания, обработать их,
+			// пропускаем файлы со стоп-словами и файлы, которые станок не обрабатывает
+			if !machine.IsJobFile(filepath.Base(fileName)) {
+				continue
+			}
+			// XML-файлы деталей дополняются размерами в именах панелей
+			if strings.ToLower(fileio.GetExtension(fileName)) == "xml" && !dryRun {
+				panel.UpdateFileWithXML(fileName)
+			}
+			fullnamesToProceed = append(fullnamesToProceed, fileName)
+		}
+		// создать плейлист
+		if len(fullnamesToProceed) > 0 {
+			if !dryRun {
+				outputString := worklist.Generate(machine.Format, fullnamesToProceed, machine.FileTypes, machine.ListPaths, settings.NameGrammar)
+				outputFilePath := filepath.Join(currentPath, machine.ListFile)
+				if fileio.CreateVerifiedFile(outputFilePath, []byte(outputString), worklist.VerifyFormat(machine.Format)) == nil {
+					logging.Info(fmt.Sprintf(i18n.Tr("Создан список заданий %s (%d файлов)"), outputFilePath, len(fullnamesToProceed)),
+						logging.FieldPath, currentPath, logging.FieldAction, "create-list", "files", len(fullnamesToProceed), "machine", machine.Name)
+				}
+				for _, group := range worklist.FindDuplicateIDs(fullnamesToProceed, settings.NameGrammar) {
+					logging.Warn(fmt.Sprintf(i18n.Tr("Одинаковый код детали у файлов %s в папке %s"), strings.Join(group, ", "), currentPath),
+						logging.FieldPath, currentPath, logging.FieldAction, "create-list")
+				}
+			}
+			//	сформировать отчёт с записью о том, что папка в работе (статус ОЖИДАЕТ)
+			//	ЗАВЕРШИТЬ выполнение функции, вернуть отчёт
+			return ReportObj{
+				ItemName:  currentPathShort,
+				Level:     0,
+				DateReady: "",
+				Status:    StatusPending,
+			}
+		}
+	}
+
+	if len(dirEntriesDirNames) > 0 {
+		sort.Strings(dirEntriesDirNames)
+		var statuses, dates []string
+		var childReports []ReportObj
+		var lev int
+		var hasOther bool
+		for _, dirName := range dirEntriesDirNames {
+			child := walk(dirName, settings, dryRun)
+			st := child.Status
+			if child.Level > lev {
+				lev = child.Level
+			}
+			// без записи обход продолжается, чтобы показать все папки, требующие участия
+			if st == StatusOther && dryRun {
+				hasOther = true
+			} else if st == StatusOther {
+				logging.Warn(fmt.Sprintf(i18n.Tr("Требуется участие пользователя: статус %s у папки %s"), StatusName(st), dirName), logging.FieldPath, dirName, logging.FieldAction, "walk")
+				return ReportObj{
+					ItemName:  currentPathShort,
+					Level:     lev + 1,
+					DateReady: "",
+					Status:    StatusOther,
+				}
+			}
+			statuses = append(statuses, st)
+			dates = append(dates, child.DateReady)
+			childReports = append(childReports, child)
+		}
+		if hasOther {
+			return ReportObj{
+				ItemName:   currentPathShort,
+				Level:      lev + 1,
+				DateReady:  "",
+				Status:     StatusOther,
+				InnerItems: childReports,
+			}
+		}
+		if fileio.HasStringInList(StatusPending, statuses) {
+			return ReportObj{
+				ItemName:   currentPathShort,
+				Level:      lev + 1,
+				DateReady:  "",
+				Status:     StatusPending,
+				InnerItems: childReports,
+			}
+		} else {
+			sort.Strings(dates)
+			readyDate := dates[len(dates)-1]
+			ready, errDate := time.Parse(time.DateOnly, readyDate)
+			if errDate != nil {
+				logging.Warn(fmt.Sprintf(i18n.Tr("Требуется участие пользователя: некорректная дата готовности %q у папки %s"), readyDate, currentPath), logging.FieldPath, currentPath, logging.FieldAction, "walk")
+				return ReportObj{
+					ItemName:  currentPathShort,
+					Level:     lev + 1,
+					DateReady: "",
+					Status:    StatusOther,
+				}
+			}
+			resReport := ReportObj{
+				ItemName:   currentPathShort,
+				Level:      lev + 1,
+				DateReady:  readyDate,
+				Status:     StatusReady,
+				InnerItems: childReports,
+			}
+			if !dryRun {
+				fileShortName := "order_ready_" + ready.Format("20060102") + ".xml"
+				resReport.WriteReportToFile(filepath.Join(currentPath, fileShortName))
+			}
+			return resReport
+		}
+	}
+	return ReportObj{
+		ItemName:  currentPathShort,
+		Level:     0,
+		DateReady: "",
+		Status:    StatusOther,
+	}
+}
@@ -0,0 +1,525 @@
+package walker_test
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProOwler/ListMaker/archive"
+	"github.com/ProOwler/ListMaker/config"
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/fixture"
+	"github.com/ProOwler/ListMaker/fixture/fixturetest"
+	"github.com/ProOwler/ListMaker/walker"
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+// Строит в памяти папку /work с настройками (extra - дополнительные элементы файла настроек)
+// и возвращает дерево SourceDir и настройки
+func newTestTree(t *testing.T, extra ...string) (fixture.Tree, config.Settings) {
+	t.Helper()
+	fixturetest.Mem(t)
+	work := fixture.New("/work")
+	var settings config.Settings
+	if err := settings.ReadFromFile(work.Settings("listMaker_settings.xml", "./src", "./done", extra...)); err != nil {
+		t.Fatal(err)
+	}
+	return fixture.New(settings.DirSource), settings
+}
+
+// Проверяет статус и дату отчёта
+func checkReport(t *testing.T, got walker.ReportObj, wantStatus string, wantDate string) {
+	t.Helper()
+	if got.Status != wantStatus || got.DateReady != wantDate {
+		t.Errorf("отчёт о папке %s: got %s %q; want %s %q", got.ItemName, got.Status, got.DateReady, wantStatus, wantDate)
+	}
+}
+
+// Проверяет наличие файла
+func checkExists(t *testing.T, path string, want bool) {
+	t.Helper()
+	_, err := fileio.Stat(path)
+	if got := err == nil; got != want {
+		t.Errorf("файл %s: существует = %t; want %t", path, got, want)
+	}
+}
+
+// Читает файл, тест прерывается при ошибке
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// 1) папка содержит list.xml => ОЖИДАЕТ, list.xml не перезаписывается
+func TestWalkList(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.List("Заказ/ЛДСП", "1_2_Бок.xml")
+	before := readFile(t, tree.Path("Заказ/ЛДСП/list.xml"))
+	// Action
+	got := walker.Walk(tree.Path("Заказ/ЛДСП"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusPending, "")
+	if after := readFile(t, tree.Path("Заказ/ЛДСП/list.xml")); string(after) != string(before) {
+		t.Errorf("list.xml перезаписан:\n%s", after)
+	}
+}
+
+// 1a) StaleLists Action="flag", файлы папки не совпадают с list.xml => ИНОЕ, list.xml не перезаписывается
+func TestWalkStaleList(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t, `<StaleLists Action="flag"/>`)
+	tree.Panel("Заказ/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Panel("Заказ/ЛДСП/3_1_Полка.xml", 600, 300, 1)
+	tree.List("Заказ/ЛДСП", "1_2_Бок.xml", "3_1_Полка.xml")
+	tree.Panel("Заказ/ЛДСП/5_1_Дно.xml", 800, 500, 1)
+	before := readFile(t, tree.Path("Заказ/ЛДСП/list.xml"))
+	// Action
+	got := walker.Walk(tree.Path("Заказ/ЛДСП"), settings)
+	check, err := walker.CheckListFile(tree.Path("Заказ/ЛДСП/list.xml"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusOther, "")
+	if after := readFile(t, tree.Path("Заказ/ЛДСП/list.xml")); string(after) != string(before) {
+		t.Errorf("list.xml перезаписан:\n%s", after)
+	}
+	if err != nil || strings.Join(check.Added, ",") != "5_1_Дно.xml" || len(check.Removed) != 0 || len(check.Changed) != 0 {
+		t.Errorf("CheckListFile: %+v, %v", check, err)
+	}
+}
+
+// 1b) StaleLists Action="regenerate" => list.xml пересоздаётся, сделанное количество неизменённых деталей сохраняется
+func TestWalkStaleListRegenerate(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t, `<StaleLists Action="regenerate"/>`)
+	tree.Panel("ЛДСП/1_2_Бок.xml", 700, 400, 2)
+	tree.Panel("ЛДСП/3_1_Полка.xml", 600, 300, 1)
+	tree.Panel("ЛДСП/4_1_Крыша.xml", 800, 400, 1)
+	tree.List("ЛДСП", "1_2_Бок.xml", "3_1_Полка.xml", "4_1_Крыша.xml", "7_1_Цоколь.xml")
+	listPath := tree.Path("ЛДСП/list.xml")
+	// станок уже сделал 2 боковины и 1 полку
+	list := strings.Replace(string(readFile(t, listPath)), "<Count>0</Count>", "<Count>2</Count>", 1)
+	list = strings.Replace(list, "<Count>0</Count>", "<Count>1</Count>", 1)
+	if err := fileio.WriteFile(listPath, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// полку заменили после передачи в работу, добавили дно, цоколь удалили
+	if err := fileio.Chtimes(tree.Path("ЛДСП/3_1_Полка.xml"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	tree.Panel("ЛДСП/5_1_Дно.xml", 800, 500, 1)
+	// Action
+	got := walker.Walk(tree.Path("ЛДСП"), settings)
+	again := walker.Walk(tree.Path("ЛДСП"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusPending, "")
+	checkReport(t, again, walker.StatusPending, "")
+	data := readFile(t, listPath)
+	counts, err := worklist.ReadCounts(worklist.FormatWorkList, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"1_2_Бок": "2", "3_1_Полка": "0", "4_1_Крыша": "0", "5_1_Дно": "0"}
+	if len(counts) != len(want) {
+		t.Errorf("Count в list.xml: %v; want %v", counts, want)
+	}
+	for serialNum, count := range want {
+		if counts[serialNum] != count {
+			t.Errorf("Count у %s: %q; want %q", serialNum, counts[serialNum], count)
+		}
+	}
+	if strings.Contains(string(data), "Цоколь") {
+		t.Errorf("в list.xml остался удалённый файл:\n%s", data)
+	}
+}
+
+// 1c) по умолчанию устаревший список не прерывает обход: готовый заказ рядом перемещается в архив
+func TestWalkStaleListBesideReadyOrder(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	fixture.New(settings.DirTarget)
+	tree.Panel("Иванов/Кухня/1_2_Бок.xml", 700, 400, 2)
+	tree.Ready("Иванов/Кухня", "20250601")
+	tree.Panel("Петров/Шкаф/1_2_Бок.xml", 700, 400, 2)
+	tree.List("Петров/Шкаф", "1_2_Бок.xml")
+	tree.Panel("Петров/Шкаф/3_1_Полка.xml", 600, 300, 1)
+	// Action
+	reports := walker.Walk(tree.Root, settings).InnerItems
+	archive.MoveReadyOrders(tree.Root, reports, settings)
+	// Assert
+	if len(reports) != 2 {
+		t.Fatalf("заказов в отчёте %d; want 2", len(reports))
+	}
+	checkReport(t, reports[0], walker.StatusReady, "2025-06-01")
+	checkReport(t, reports[1], walker.StatusPending, "")
+	checkExists(t, filepath.Join(settings.DirTarget, "2025-06", "Иванов", "order_ready_20250601.xml"), true)
+	checkExists(t, tree.Path("Иванов"), false)
+}
+
+// 2) ready_fasady.xml раскладывается по папкам с фасадами, обработка продолжается
+func TestWalkReadyFasady(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/Фасады/1_2_Фасад.xml", 716, 396, 2)
+	tree.Panel("Заказ/Корпус/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Заказ/Корпус/ЛДСП", "20250601")
+	tree.ReadyFasady("Заказ", time.Date(2025, 6, 3, 10, 0, 0, 0, time.Local))
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkExists(t, tree.Path("Заказ/Фасады/ready_20250603.xml"), true)
+	checkReport(t, got, walker.StatusReady, "2025-06-03")
+	checkExists(t, tree.Path("Заказ/order_ready_20250603.xml"), true)
+}
+
+// 2) в папку с фасадами, где уже есть list.xml, копия ready_yyyymmdd.xml не кладётся
+func TestWalkReadyFasadyWithList(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/Фасады МДФ/1_2_Фасад.xml", 716, 396, 2)
+	tree.List("Заказ/Фасады МДФ", "1_2_Фасад.xml")
+	tree.Panel("Заказ/Фасады Шпон/1_1_Фасад.xml", 716, 396, 1)
+	tree.ReadyFasady("Заказ", time.Date(2025, 6, 3, 10, 0, 0, 0, time.Local))
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkExists(t, tree.Path("Заказ/Фасады МДФ/ready_20250603.xml"), false)
+	checkExists(t, tree.Path("Заказ/Фасады Шпон/ready_20250603.xml"), true)
+	checkReport(t, got, walker.StatusPending, "")
+}
+
+// 2) ready_fasady.xml без папок с фасадами => ОЖИДАЕТ, ничего не создаётся
+func TestWalkReadyFasadyWithoutDirs(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/Корпус/1_2_Бок.xml", 700, 400, 1)
+	tree.ReadyFasady("Заказ", time.Date(2025, 6, 3, 10, 0, 0, 0, time.Local))
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusPending, "")
+	checkExists(t, tree.Path("Заказ/Корпус/list.xml"), false)
+}
+
+// 3) папка содержит ready_yyyymmdd.xml => ГОТОВ с датой из имени файла
+func TestWalkReady(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("ЛДСП", "20250601")
+	// Action
+	got := walker.Walk(tree.Path("ЛДСП"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusReady, "2025-06-01")
+	checkExists(t, tree.Path("ЛДСП/list.xml"), false)
+}
+
+// 3) файл готовности без даты в имени => ОЖИДАЕТ
+func TestWalkReadyWithoutDate(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("ЛДСП", "2025")
+	// Action
+	got := walker.Walk(tree.Path("ЛДСП"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusPending, "")
+}
+
+// 3) папка содержит метку order_ready => отчёт читается из метки, вложенные папки не обходятся
+func TestWalkOrderMarker(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/Кухня/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Заказ/Кухня/ЛДСП", "20250601")
+	marker := walker.Walk(tree.Path("Заказ"), settings)
+	tree.Panel("Заказ/Кухня/МДФ/1_1_Фасад.xml", 700, 400, 1)
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusReady, "2025-06-01")
+	if len(got.InnerItems) != 1 || got.Checksum != marker.Checksum {
+		t.Errorf("отчёт не прочитан из метки: %+v", got)
+	}
+	checkExists(t, tree.Path("Заказ/Кухня/МДФ/list.xml"), false)
+}
+
+// 2а) рядом с меткой остался временный файл прерванной записи => он не читается, метка действует
+func TestWalkOrderMarkerWithTempFile(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/Кухня/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Заказ/Кухня/ЛДСП", "20250601")
+	walker.Walk(tree.Path("Заказ"), settings)
+	if err := fileio.WriteFile(tree.Path("Заказ/.order_ready_20250601.xml.123-456.tmp"), []byte("<Root>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusReady, "2025-06-01")
+}
+
+// 3) повреждённая метка order_ready => ИНОЕ
+func TestWalkBrokenOrderMarker(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Dir("Заказ/Кухня")
+	if err := fileio.WriteFile(tree.Path("Заказ/order_ready_20250601.xml"), []byte("<Root>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusOther, "")
+}
+
+// 3а) из метки удалён атрибут Version, сумма осталась => метка не считается файлом версии 1, ИНОЕ
+func TestWalkOrderMarkerWithoutVersion(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.OrderMarker("Заказ", walker.ReportObj{ItemName: "Кухня", DateReady: "2025-06-01", Status: walker.StatusReady})
+	markerPath := tree.Path("Заказ/order_ready_20250601.xml")
+	data := strings.Replace(string(readFile(t, markerPath)), ` Version="3"`, "", 1)
+	if err := fileio.WriteFile(markerPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusOther, "")
+	if after := string(readFile(t, markerPath)); after != data {
+		t.Errorf("метка переписана:\n%s", after)
+	}
+}
+
+// 4) папка содержит файлы-задания => создаётся list.xml, у панелей заполняется Name, ОЖИДАЕТ
+func TestWalkTasks(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("ЛДСП/10_1_Крыша.xml", 800, 400, 1)
+	tree.Panel("ЛДСП/1.2_3_Дверь.xml", 716.5, 396.25, 3)
+	tree.MPR("ЛДСП/2_1_Фреза.mpr")
+	tree.Panel("ЛДСП/1_2_Бок_list.xml", 700, 400, 1)
+	// Action
+	got := walker.Walk(tree.Path("ЛДСП"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusPending, "")
+	fixturetest.Golden(t, "tasks_list.xml", readFile(t, tree.Path("ЛДСП/list.xml")))
+	fixturetest.Golden(t, "tasks_panel.xml", readFile(t, tree.Path("ЛДСП/1.2_3_Дверь.xml")))
+}
+
+// 4а) список работ составляется для станка, выбранного по файлу-метке или имени папки
+func TestWalkTasksMachines(t *testing.T) {
+	// Arrange
+	machines := `<Machines>
+		<Machine Name="Раскрой" Pattern="ЛДСП*"><FileType Code="11" Ext="xml"/></Machine>
+		<Machine Name="Присадка" ListFile="drill.txt" Format="text" MarkerFile="drill.machine"><FileType Code="7" Ext="mpr"/></Machine>
+	</Machines>`
+	tree, settings := newTestTree(t, machines)
+	tree.Panel("ЛДСП Белый/1_2_Бок.xml", 700, 400, 2)
+	tree.MPR("ЛДСП Белый/2_1_Фреза.mpr")
+	tree.Panel("ЛДСП Дуб/10_1_Крыша.xml", 800, 400, 1)
+	tree.MPR("ЛДСП Дуб/10_1_Паз.mpr")
+	tree.MPR("ЛДСП Дуб/2_4_Отверстия.mpr")
+	tree.MPR("ЛДСП Дуб/drill.machine")
+	// Action
+	nesting := walker.Walk(tree.Path("ЛДСП Белый"), settings)
+	drilling := walker.Walk(tree.Path("ЛДСП Дуб"), settings)
+	again := walker.Walk(tree.Path("ЛДСП Дуб"), settings)
+	// Assert
+	checkReport(t, nesting, walker.StatusPending, "")
+	checkReport(t, drilling, walker.StatusPending, "")
+	checkReport(t, again, walker.StatusPending, "")
+	if list := string(readFile(t, tree.Path("ЛДСП Белый/list.xml"))); strings.Contains(list, "Фреза") || !strings.Contains(list, "1_2_Бок.xml") {
+		t.Errorf("в list.xml раскроя не те файлы:\n%s", list)
+	}
+	checkExists(t, tree.Path("ЛДСП Дуб/list.xml"), false)
+	want := tree.Path("ЛДСП Дуб/2_4_Отверстия.mpr") + "\t4\r\n" + tree.Path("ЛДСП Дуб/10_1_Паз.mpr") + "\t1\r\n"
+	if got := string(readFile(t, tree.Path("ЛДСП Дуб/drill.txt"))); got != want {
+		t.Errorf("drill.txt:\n%q\nwant\n%q", got, want)
+	}
+}
+
+// 4б) имена файлов разбираются по правилам FileNames из настроек
+func TestWalkTasksFileNames(t *testing.T) {
+	// Arrange
+	fileNames := `<FileNames DateLayout="2006-01-02">
+		<Pattern><![CDATA[^(?P<id>[A-Z]+-[\d.]+) x(?P<qty>\d+)$]]></Pattern>
+		<ReadyPattern><![CDATA[^ready (?P<date>\d{4}-\d{2}-\d{2})$]]></ReadyPattern>
+	</FileNames>`
+	tree, settings := newTestTree(t, fileNames)
+	tree.Panel("Заказ/ЛДСП/DET-10 x1.xml", 800, 400, 1)
+	tree.Panel("Заказ/ЛДСП/DET-2 x4.xml", 700, 400, 4)
+	tree.Panel("Заказ/МДФ/DET-1 x1.xml", 700, 400, 1)
+	if err := fileio.WriteFile(tree.Path("Заказ/МДФ/ready 2025-06-01.xml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Action
+	tasks := walker.Walk(tree.Path("Заказ/ЛДСП"), settings)
+	ready := walker.Walk(tree.Path("Заказ/МДФ"), settings)
+	// Assert
+	checkReport(t, tasks, walker.StatusPending, "")
+	checkReport(t, ready, walker.StatusReady, "2025-06-01")
+	list := string(readFile(t, tree.Path("Заказ/ЛДСП/list.xml")))
+	if first, second := strings.Index(list, "<SerialNum>DET-2 x4</SerialNum>"), strings.Index(list, "<SerialNum>DET-10 x1</SerialNum>"); first < 0 || second < first ||
+		!strings.Contains(list, "<PlanCount>4</PlanCount>") {
+		t.Errorf("list.xml составлен не по правилам FileNames:\n%s", list)
+	}
+}
+
+// 5) во всех подпапках есть метки готовности => создаётся метка order_ready с последней датой, ГОТОВ
+func TestWalkAllReady(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Иванов/Кухня/ЛДСП Белый/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Иванов/Кухня/ЛДСП Белый", "20250601")
+	tree.Panel("Иванов/Кухня/ЛДСП Серый/2_1_Полка.xml", 500, 300, 1)
+	tree.Ready("Иванов/Кухня/ЛДСП Серый", "20250603")
+	// Action
+	got := walker.Walk(tree.Path("Иванов"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusReady, "2025-06-03")
+	checkExists(t, tree.Path("Иванов/Кухня/order_ready_20250603.xml"), true)
+	fixturetest.Golden(t, "all_ready_marker.xml", readFile(t, tree.Path("Иванов/order_ready_20250603.xml")))
+}
+
+// 6) не во всех подпапках есть метки готовности => ОЖИДАЕТ, метка не создаётся
+func TestWalkSomePending(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Заказ/ЛДСП", "20250601")
+	tree.Panel("Заказ/МДФ/1_1_Фасад.xml", 700, 400, 1)
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusPending, "")
+	if len(got.InnerItems) != 2 {
+		t.Errorf("вложенных отчётов %d; want 2", len(got.InnerItems))
+	}
+	checkExists(t, tree.Path("Заказ/order_ready_20250601.xml"), false)
+}
+
+// 6) у одной из подпапок статус ИНОЕ => ИНОЕ
+func TestWalkChildOther(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Заказ/ЛДСП", "20250601")
+	tree.Dir("Заказ/Пусто")
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusOther, "")
+}
+
+// 7) пустая папка => ИНОЕ
+func TestWalkEmpty(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Dir("Пусто")
+	// Action
+	got := walker.Walk(tree.Path("Пусто"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusOther, "")
+}
+
+// Игнорируемые папки не обходятся
+func TestWalkIgnored(t *testing.T) {
+	// Arrange
+	tree, settings := newTestTree(t)
+	tree.Panel("Заказ/ЛДСП/1_2_Бок.xml", 700, 400, 1)
+	tree.Ready("Заказ/ЛДСП", "20250601")
+	tree.Panel("Заказ/#ВЫПОЛНЕННЫЕ/1_2_Бок.xml", 700, 400, 1)
+	// Action
+	got := walker.Walk(tree.Path("Заказ"), settings)
+	// Assert
+	checkReport(t, got, walker.StatusReady, "2025-06-01")
+	checkExists(t, tree.Path("Заказ/#ВЫПОЛНЕННЫЕ/list.xml"), false)
+}
+
+// Синтетическое дерево: повторный обход ничего не меняет в статусах
+func TestWalkGeneratedTwice(t *testing.T) {
+	// Arrange
+	fixturetest.Mem(t)
+	tree := fixture.Generate("/src", fixture.Spec{Customers: 3, Orders: 2, Projects: 2, Materials: 3, Fasady: true, Seed: 1})
+	var settings config.Settings
+	settings.FasadyPatterns = []string{"*фасад*"}
+	// Action
+	first := walker.Walk(tree.Root, settings)
+	second := walker.Walk(tree.Root, settings)
+	// Assert
+	if len(first.InnerItems) != 3 || len(second.InnerItems) != 3 {
+		t.Fatalf("заказчиков %d и %d; want 3", len(first.InnerItems), len(second.InnerItems))
+	}
+	for i := range first.InnerItems {
+		if first.InnerItems[i].Status != second.InnerItems[i].Status || first.InnerItems[i].DateReady != second.InnerItems[i].DateReady {
+			t.Errorf("%s: первый обход %s %q, второй %s %q", first.InnerItems[i].ItemName,
+				first.InnerItems[i].Status, first.InnerItems[i].DateReady, second.InnerItems[i].Status, second.InnerItems[i].DateReady)
+		}
+	}
+}
+
+// Снимок всех файлов дерева: путь -> содержимое и время изменения
+func snapshotTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	result := make(map[string]string)
+	err := fileio.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, errStat := fileio.Stat(path)
+		if errStat != nil {
+			return errStat
+		}
+		result[path] = info.ModTime().String() + "\n" + string(readFile(t, path))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// Обход без изменений: файлы не меняются, статусы заказов - как после обработки
+func TestWalkReadOnly(t *testing.T) {
+	// Arrange
+	fixturetest.Mem(t)
+	tree := fixture.Generate("/src", fixture.Spec{Customers: 3, Orders: 2, Projects: 2, Materials: 3, Seed: 3})
+	tree.Panel("Иванов/Новый/ЛДСП/1_2_Бок.xml", 700, 400, 2)
+	tree.Dir("Иванов/Пусто")
+	var settings config.Settings
+	before := snapshotTree(t, tree.Root)
+	// Action
+	preview := walker.WalkReadOnly(tree.Root, settings)
+	after := snapshotTree(t, tree.Root)
+	// Assert
+	if len(after) != len(before) {
+		t.Errorf("файлов до обхода %d, после %d", len(before), len(after))
+	}
+	for path, content := range before {
+		if after[path] != content {
+			t.Errorf("файл %s изменён", path)
+		}
+	}
+	checkReport(t, preview, walker.StatusOther, "")
+	for _, customer := range preview.InnerItems {
+		if customer.ItemName == "Иванов" {
+			// обход без изменений не прерывается на папке ИНОЕ
+			checkReport(t, customer, walker.StatusOther, "")
+			if len(customer.InnerItems) < 2 {
+				t.Errorf("у заказчика Иванов в отчёте %d папок; want все вложенные", len(customer.InnerItems))
+			}
+			continue
+		}
+		processed := walker.Walk(tree.Path(customer.ItemName), settings)
+		checkReport(t, customer, processed.Status, processed.DateReady)
+	}
+}
@@ -0,0 +1,189 @@
+package worklist
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/panel"
+)
+
+// форматы списка работ
+const (
+	FormatWorkList = "worklist" // XML WorkList (FileList и ProcessList), как list.xml
+	FormatText     = "text"     // текст: в каждой строке путь к файлу, табуляция, количество деталей
+)
+
+/**
+ * IsKnownFormat: Проверяет, что формат списка работ поддерживается.
+ */
+func IsKnownFormat(format string) bool {
+	return format == FormatWorkList || format == FormatText
+}
+
+/**
+ * Generate: Формирует содержимое списка работ в заданном формате.
+ * @param format - FormatWorkList или FormatText.
+ * @param myPathList - Список полных путей к файлам-заданиям.
+ * @param extCodes - Карта кодов для расширений файлов (для FormatWorkList).
+ * @param mapping - Правила записи путей.
+ * @param grammar - Правила разбора имён файлов (код детали, количество); nil - по умолчанию.
+ * @return string - Содержимое файла списка.
+ */
+func Generate(format string, myPathList []string, extCodes FileFormats, mapping PathMapping, grammar *panel.NameGrammar) string {
+	return Regenerate(format, myPathList, extCodes, mapping, nil, grammar)
+}
+
+/**
+ * Regenerate: Формирует содержимое списка работ взамен устаревшего, сохраняя сделанное станком количество.
+ * @param format - FormatWorkList или FormatText (в текстовом списке количество не хранится).
+ * @param myPathList - Список полных путей к файлам-заданиям.
+ * @param extCodes - Карта кодов для расширений файлов.
+ * @param mapping - Правила записи путей.
+ * @param counts - Сделанное количество (Count) по коду детали (SerialNum), см. ReadCounts.
+ * @param grammar - Правила разбора имён файлов; nil - по умолчанию.
+ * @return string - Содержимое файла списка.
+ */
+func Regenerate(format string, myPathList []string, extCodes FileFormats, mapping PathMapping, counts map[string]string, grammar *panel.NameGrammar) string {
+	if format == FormatText {
+		return GetOutputText(myPathList, mapping, grammar)
+	}
+	return getOutputXML(myPathList, extCodes, mapping, counts, grammar)
+}
+
+/**
+ * ReadCounts: Возвращает сделанное станком количество (Count) по кодам деталей (SerialNum) из списка работ.
+ * Записи с некорректным Count пропускаются.
+ * @param format - Формат списка; у текстового списка количества нет.
+ * @param data - Содержимое файла списка.
+ * @return map[string]string - SerialNum -> Count.
+ * @return error - Ошибка разбора XML.
+ */
+func ReadCounts(format string, data []byte) (map[string]string, error) {
+	counts := make(map[string]string)
+	if format == FormatText {
+		return counts, nil
+	}
+	var workList XWorkList
+	decoded, _ := fileio.DecodeXML(data)
+	if err := xml.Unmarshal(decoded, &workList); err != nil {
+		return nil, err
+	}
+	for _, item := range workList.ProcessList.Item {
+		count := strings.TrimSpace(item.Count)
+		if _, err := strconv.Atoi(count); err == nil && !strings.HasPrefix(count, "-") && !strings.HasPrefix(count, "+") {
+			counts[strings.TrimSpace(item.SerialNum)] = count
+		}
+	}
+	return counts, nil
+}
+
+/**
+ * ReadProgress: Подсчитывает по списку работ, сколько деталей сделано станком (Count) и сколько нужно (PlanCount).
+ * Записи с некорректными числами не учитываются.
+ * @param format - Формат списка; у текстового списка количества нет (ok = false).
+ * @param data - Содержимое файла списка.
+ * @return done, planned - Сделано и нужно деталей.
+ * @return ok - false, если прогресс по списку не определить.
+ */
+func ReadProgress(format string, data []byte) (done int, planned int, ok bool) {
+	if format == FormatText {
+		return 0, 0, false
+	}
+	var workList XWorkList
+	decoded, _ := fileio.DecodeXML(data)
+	if err := xml.Unmarshal(decoded, &workList); err != nil {
+		return 0, 0, false
+	}
+	for _, item := range workList.ProcessList.Item {
+		planCount, errPlan := strconv.Atoi(strings.TrimSpace(item.PlanCount))
+		count, errCount := strconv.Atoi(strings.TrimSpace(item.Count))
+		if errPlan != nil || errCount != nil || planCount < 0 || count < 0 {
+			continue
+		}
+		done += count
+		planned += planCount
+	}
+	return done, planned, planned > 0
+}
+
+/**
+ * VerifyFormat: Возвращает проверку записанного списка работ для fileio.CreateVerifiedFile.
+ */
+func VerifyFormat(format string) func([]byte) error {
+	if format == FormatText {
+		return VerifyText
+	}
+	return Verify
+}
+
+/**
+ * GetOutputText: Формирует текстовый список работ: строки "путь<TAB>количество" (CRLF)
+ * в порядке кодов деталей (см. SortFilenames). Если количество из имени не извлекается, оно не пишется.
+ * @param myPathList - Список полных путей к файлам-заданиям.
+ * @param mapping - Правила записи путей.
+ * @param grammar - Правила разбора имён файлов; nil - по умолчанию.
+ * @return string - Содержимое файла списка.
+ */
+func GetOutputText(myPathList []string, mapping PathMapping, grammar *panel.NameGrammar) string {
+	fullPaths := make(map[string]string, len(myPathList))
+	for _, pathEntry := range myPathList {
+		fullPaths[filepath.Base(pathEntry)] = pathEntry
+	}
+	var sb strings.Builder
+	for _, name := range SortFilenames(myPathList, grammar) {
+		pathEntry := fullPaths[name]
+		sb.WriteString(mapping.Apply(pathEntry, filepath.Dir(pathEntry)))
+		sb.WriteString("\t")
+		if detailCount, err := grammar.CountDetails(strings.TrimSuffix(name, filepath.Ext(name))); err == nil {
+			sb.WriteString(detailCount)
+		}
+		sb.WriteString("\r\n")
+	}
+	return sb.String()
+}
+
+// Проверяет, что текстовый список работ не пуст
+func VerifyText(data []byte) error {
+	paths, err := ReadFilePaths(FormatText, data)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf(i18n.Tr("список файлов пуст"))
+	}
+	return nil
+}
+
+/**
+ * ReadFilePaths: Возвращает пути к файлам-заданиям из списка работ так, как они записаны (FilePath).
+ * @param format - Формат списка.
+ * @param data - Содержимое файла списка.
+ * @return []string - Пути без начальных и конечных пробелов.
+ * @return error - Ошибка разбора XML.
+ */
+func ReadFilePaths(format string, data []byte) ([]string, error) {
+	var result []string
+	if format == FormatText {
+		for _, line := range strings.Split(string(data), "\n") {
+			filePath, _, _ := strings.Cut(strings.TrimRight(line, "\r"), "\t")
+			if filePath = strings.TrimSpace(filePath); filePath != "" {
+				result = append(result, filePath)
+			}
+		}
+		return result, nil
+	}
+	var workList XWorkList
+	decoded, _ := fileio.DecodeXML(data)
+	if err := xml.Unmarshal(decoded, &workList); err != nil {
+		return nil, err
+	}
+	for _, item := range workList.FileList.Item {
+		result = append(result, strings.TrimSpace(item.FilePath))
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package worklist
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/i18n"
+)
+
+// разделители путей в FilePath
+const (
+	SeparatorKeep    = ""        // как в системе, где работает программа
+	SeparatorWindows = "windows" // обратная косая черта
+	SeparatorUnix    = "unix"    // прямая косая черта
+)
+
+// PathRule: Замена начала пути: From - путь на компьютере, где работает программа, To - тот же путь на станке
+type PathRule struct {
+	From string
+	To   string
+}
+
+// PathMapping: Правила записи путей FilePath в list.xml
+type PathMapping struct {
+	Relative  bool       // Пути относительно папки list.xml (правила замены не применяются)
+	Separator string     // SeparatorKeep, SeparatorWindows или SeparatorUnix
+	Rules     []PathRule // Замены начала пути, применяется первая подходящая
+}
+
+/**
+ * Validate: Проверяет правила записи путей.
+ * @return error - Ошибка, если разделитель неизвестен или у замены не указано начало пути.
+ */
+func (mapping PathMapping) Validate() error {
+	switch mapping.Separator {
+	case SeparatorKeep, SeparatorWindows, SeparatorUnix:
+	default:
+		return fmt.Errorf(i18n.Tr("неизвестный разделитель путей %q"), mapping.Separator)
+	}
+	for _, rule := range mapping.Rules {
+		if strings.TrimSpace(rule.From) == "" || strings.TrimSpace(rule.To) == "" {
+			return fmt.Errorf(i18n.Tr("в замене пути %q -> %q не указано начало пути"), rule.From, rule.To)
+		}
+	}
+	return nil
+}
+
+/**
+ * Apply: Преобразует путь к файлу-заданию в путь, по которому файл видит станок.
+ * @param filePath - Полный путь к файлу на компьютере, где работает программа.
+ * @param listDir - Папка, в которой создаётся list.xml.
+ * @return string - Значение FilePath.
+ */
+func (mapping PathMapping) Apply(filePath string, listDir string) string {
+	result := filePath
+	if mapping.Relative {
+		if relPath, err := filepath.Rel(listDir, filePath); err == nil {
+			result = relPath
+		}
+	} else {
+		for _, rule := range mapping.Rules {
+			if rest, ok := cutPathPrefix(filePath, rule.From); ok {
+				result = rule.To + rest
+				break
+			}
+		}
+	}
+	switch mapping.Separator {
+	case SeparatorWindows:
+		result = strings.ReplaceAll(result, "/", `\`)
+	case SeparatorUnix:
+		result = strings.ReplaceAll(result, `\`, "/")
+	}
+	return result
+}
+
+/**
+ * Resolve: Обратное преобразование: путь FilePath из list.xml в путь на компьютере, где работает программа.
+ * Относительный путь отсчитывается от папки list.xml. Путь Windows (Z:\..., \\server\...),
+ * не подходящий ни к одной замене, возвращается как есть.
+ * @param listedPath - Значение FilePath.
+ * @param listDir - Папка, в которой лежит list.xml.
+ * @return string - Путь к файлу.
+ */
+func (mapping PathMapping) Resolve(listedPath string, listDir string) string {
+	result := listedPath
+	for _, rule := range mapping.Rules {
+		if rest, ok := cutPathPrefix(listedPath, rule.To); ok {
+			result = rule.From + rest
+			break
+		}
+	}
+	if filepath.Separator != '\\' && isWindowsAbs(result) {
+		return result
+	}
+	result = filepath.FromSlash(strings.ReplaceAll(result, `\`, "/"))
+	if !filepath.IsAbs(result) {
+		result = filepath.Join(listDir, result)
+	}
+	return filepath.Clean(result)
+}
+
+/**
+ * cutPathPrefix: Отрезает начало пути без учёта регистра и вида разделителей.
+ * Начало должно совпадать с целыми папками: /mnt/share подходит к /mnt/share/Иванов, но не к /mnt/shared.
+ * @return string - Остаток пути, начинающийся с разделителя, или пустая строка.
+ * @return bool - true, если путь начинается с prefix.
+ */
+func cutPathPrefix(path string, prefix string) (string, bool) {
+	toSlash := func(s string) string {
+		return strings.ReplaceAll(s, `\`, "/")
+	}
+	prefix = strings.TrimRight(toSlash(prefix), "/")
+	if prefix == "" || len(path) < len(prefix) || !strings.EqualFold(toSlash(path[:len(prefix)]), prefix) {
+		return "", false
+	}
+	rest := path[len(prefix):]
+	if rest != "" && rest[0] != '/' && rest[0] != '\\' {
+		return "", false
+	}
+	return rest, true
+}
+
+// Проверяет, что путь - абсолютный путь Windows (с буквой диска или сетевой)
+func isWindowsAbs(path string) bool {
+	if strings.HasPrefix(path, `\\`) {
+		return true
+	}
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/') &&
+		(path[0] >= 'a' && path[0] <= 'z' || path[0] >= 'A' && path[0] <= 'Z')
+}
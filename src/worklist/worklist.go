@@ -0,0 +1,317 @@
+// Пакет worklist - формирование списка работ list.xml для станка.
+package worklist
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ProOwler/ListMaker/fileio"
+	"github.com/ProOwler/ListMaker/i18n"
+	"github.com/ProOwler/ListMaker/logging"
+	"github.com/ProOwler/ListMaker/panel"
+)
+
+// XWorkList: Структура для разбора файла list.xml
+type XWorkList struct {
+	XMLName     xml.Name     `xml:"WorkList"`
+	FileList    XFileList    `xml:"FileList"`
+	ProcessList XProcessList `xml:"ProcessList"`
+}
+
+// XFileList: Список файлов-заданий в list.xml
+type XFileList struct {
+	Item []XFileItem `xml:"Item"`
+}
+
+// XFileItem: Файл-задание в list.xml
+type XFileItem struct {
+	FileType string `xml:"FileType"`
+	FilePath string `xml:"FilePath"`
+}
+
+// XProcessList: Список деталей в list.xml
+type XProcessList struct {
+	Item []XProcessItem `xml:"Item"`
+}
+
+// XProcessItem: Деталь в list.xml: сколько нужно (PlanCount) и сколько уже сделано станком (Count)
+type XProcessItem struct {
+	SerialNum string `xml:"SerialNum"`
+	PlanCount string `xml:"PlanCount"`
+	Count     string `xml:"Count"`
+}
+
+// FileFormats: Пользовательский тип для хранения сопоставлений (например, кодов и расширений файлов)
+type FileFormats map[string]string
+
+// Имя файла, генерируемого в каждой папке
+const ListFileName = "list.xml"
+
+// константы, как обрабатывать файлы в папке
+const (
+	c_PROC_NO = iota
+	c_PROC_XML
+	c_PROC_MPR
+)
+
+// коды типов файлов-заданий в list.xml
+var DefaultFileFormats = FileFormats{
+	"7":  "mpr", // Код "7" для файлов .mpr
+	"11": "xml", // Код "11" для файлов .xml
+}
+
+// Проверяет, что содержимое list.xml разбирается и содержит файлы-задания
+func Verify(data []byte) error {
+	var workList XWorkList
+	if err := xml.Unmarshal(data, &workList); err != nil {
+		return err
+	}
+	if len(workList.FileList.Item) == 0 {
+		return fmt.Errorf(i18n.Tr("список файлов пуст"))
+	}
+	return nil
+}
+
+/**
+ * GetOutputXML: Формирует строку с итоговым XML для файла list.xml.
+ * @param myPathList - Список полных путей к обработанным файлам (.mpr, .xml).
+ * @param extCodes - Карта кодов для расширений файлов.
+ * @param mapping - Правила записи путей FilePath (list.xml создаётся в папке файлов-заданий).
+ * @param grammar - Правила разбора имён файлов (код детали, количество); nil - по умолчанию.
+ * @return string - Строка с содержимым list.xml.
+ */
+func GetOutputXML(myPathList []string, extCodes FileFormats, mapping PathMapping, grammar *panel.NameGrammar) string {
+	return getOutputXML(myPathList, extCodes, mapping, nil, grammar)
+}
+
+// Формирует list.xml; counts - сделанное станком количество по SerialNum (nil - всё с нуля)
+func getOutputXML(myPathList []string, extCodes FileFormats, mapping PathMapping, counts map[string]string, grammar *panel.NameGrammar) string {
+	// Используем strings.Builder для эффективного построения строки
+	var sb strings.Builder
+
+	sb.WriteString(`<?xml version="1.0" encoding="utf-8" ?>`)                // Добавляем заголовок XML
+	sb.WriteString("\n<WorkList>\n")                                         // Открываем корневой элемент
+	sb.WriteString("	<Version><Major>1</Major><Minor>0</Minor></Version>\n") // Версия
+	sb.WriteString("	<FileList>\n")                                          // Секция списка файлов
+	sb.WriteString(getXMLFileList(myPathList, extCodes, mapping))            // Генерируем элементы Item для файлов
+	sb.WriteString("	</FileList>\n")                                         // Закрываем секцию списка файлов
+	sb.WriteString("	<ProcessList>\n")                                       // Секция списка процессов
+	sb.WriteString(getXMLProcessList(myPathList, counts, grammar))           // Генерируем элементы Item для процессов
+	sb.WriteString("	</ProcessList>\n")                                      // Закрываем секцию списка процессов
+	sb.WriteString("</WorkList>\n")                                          // Закрываем корневой элемент
+
+	return sb.String()
+}
+
+/**
+ * getXMLFileList: Формирует часть XML (<Item>...</Item>) для списка файлов в list.xml.
+ * @param myPathList - Список полных путей к файлам.
+ * @param extCodes - Карта кодов для расширений.
+ * @param mapping - Правила записи путей.
+ * @return string - XML-строка со списком файлов.
+ */
+func getXMLFileList(myPathList []string, extCodes FileFormats, mapping PathMapping) string {
+	var sb strings.Builder
+	for _, pathEntry := range myPathList {
+		sb.WriteString("		<Item>\n")
+		sb.WriteString("			<FileType>")
+		sb.WriteString(getFiletypeCode(extCodes, fileio.GetExtension(pathEntry))) // Получаем код типа файла
+		sb.WriteString("</FileType>\n")
+		sb.WriteString("			<FilePath>")
+		// Экранируем специальные символы XML в пути к файлу
+		xml.EscapeText(&sb, []byte(mapping.Apply(pathEntry, filepath.Dir(pathEntry))))
+		sb.WriteString("</FilePath>\n")
+		sb.WriteString("		</Item>\n")
+	}
+	return sb.String()
+}
+
+/**
+ * getXMLProcessList: Формирует часть XML (<Item>...</Item>) для списка процессов в list.xml.
+ * Извлекает код детали и количество из имени файла.
+ * @param myPathList - Список полных путей к файлам.
+ * @param counts - Сделанное количество по коду детали (SerialNum), сохраняемое при обновлении списка; nil - 0.
+ * @param grammar - Правила разбора имён файлов.
+ * @return string - XML-строка со списком процессов.
+ */
+func getXMLProcessList(myPathList []string, counts map[string]string, grammar *panel.NameGrammar) string {
+	var sb strings.Builder
+	for _, elemPath := range SortFilenames(myPathList, grammar) {
+		detailCode := strings.TrimSuffix(elemPath, filepath.Ext(elemPath)) // Убираем расширение
+		detailCount, err := grammar.CountDetails(detailCode)               // Извлекаем количество из имени файла
+
+		if err == nil { // Добавляем только если удалось извлечь количество
+			sb.WriteString("		<Item>\n")
+			sb.WriteString("			<SerialNum>")
+			xml.EscapeText(&sb, []byte(detailCode)) // Экранируем код детали
+			sb.WriteString("</SerialNum>\n")
+			sb.WriteString("			<PlanCount>")
+			xml.EscapeText(&sb, []byte(detailCount)) // Экранируем количество
+			sb.WriteString("</PlanCount>\n")
+			sb.WriteString("			<Count>")
+			if count, ok := counts[detailCode]; ok {
+				xml.EscapeText(&sb, []byte(count))
+			} else {
+				sb.WriteString("0") // Поле Count по умолчанию 0
+			}
+			sb.WriteString("</Count>\n")
+			sb.WriteString("		</Item>\n")
+		} else {
+			logging.Warn(fmt.Sprintf(i18n.Tr("Предупреждение: Не удалось извлечь количество деталей из имени файла '%s' (%v). Запись в ProcessList не добавлена."), elemPath, err),
+				logging.FieldPath, elemPath, logging.FieldAction, "create-list", logging.FieldError, err)
+		}
+	}
+	return sb.String()
+}
+
+// --- Вспомогательные функции
+
+// Файл и составные части кода детали из его имени (12.0.3 - ["12", "0", "3"])
+type sortItem struct {
+	name string
+	id   []string
+}
+
+/**
+ * SortFilenames: Сортирует имена файлов по коду детали в естественном порядке:
+ *  код детали (по умолчанию первая часть имени до "_") разбивается по "." на составные части,
+ *  части сравниваются по очереди, числа в них - по значению без учёта нулей в старших разрядах
+ *  (1.2 < 1.10 < 2 < 10, 01 = 1), код-префикс идёт раньше более длинного (1 < 1.1).
+ *  Файлы с одинаковым кодом упорядочиваются по полному имени; ни один файл не теряется.
+ *  Файлы без кода детали идут первыми.
+ * @param unorderedFilelist - Список ПОЛНЫХ имён файлов, подлежащий сортировке
+ * @param grammar - Правила разбора имён файлов (код детали); nil - по умолчанию.
+ * @return - Пересортированный список, БЕЗ полного пути
+ */
+func SortFilenames(unorderedFilelist []string, grammar *panel.NameGrammar) []string {
+	items := sortItems(unorderedFilelist, grammar)
+	resList := make([]string, 0, len(items))
+	for _, item := range items {
+		resList = append(resList, item.name)
+	}
+	return resList
+}
+
+/**
+ * FindDuplicateIDs: Находит файлы с одинаковым кодом детали (порядок таких файлов в ProcessList
+ * определяется только их именами, и, скорее всего, один из кодов указан по ошибке).
+ * @param fileList - Список полных имён файлов.
+ * @param grammar - Правила разбора имён файлов (код детали); nil - по умолчанию.
+ * @return [][]string - Группы имён файлов (без пути) с одинаковым кодом, в порядке сортировки.
+ */
+func FindDuplicateIDs(fileList []string, grammar *panel.NameGrammar) [][]string {
+	var duplicates [][]string
+	items := sortItems(fileList, grammar)
+	for i := 0; i < len(items); {
+		j := i + 1
+		for j < len(items) && len(items[i].id) > 0 && compareIDs(items[i].id, items[j].id) == 0 {
+			j++
+		}
+		if j-i > 1 {
+			var group []string
+			for _, item := range items[i:j] {
+				group = append(group, item.name)
+			}
+			duplicates = append(duplicates, group)
+		}
+		i = j
+	}
+	return duplicates
+}
+
+// Отбрасывает путь к папке, разбирает коды деталей и сортирует файлы (см. SortFilenames)
+func sortItems(fileList []string, grammar *panel.NameGrammar) []sortItem {
+	isSep := func(c rune) bool {
+		return c == '.'
+	}
+	items := make([]sortItem, 0, len(fileList))
+	for _, el := range fileList {
+		name := filepath.Base(el)
+		//идентификатор в имени файла, например, 12.0.3; у файла без кода - пустой
+		aydee, _ := grammar.GetPartFromDividedString(strings.TrimSuffix(name, filepath.Ext(name)), panel.PartID)
+		items = append(items, sortItem{name: name, id: strings.FieldsFunc(aydee, isSep)})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if c := compareIDs(items[i].id, items[j].id); c != 0 {
+			return c < 0
+		}
+		return items[i].name < items[j].name
+	})
+	return items
+}
+
+/**
+ * compareIDs: Сравнивает коды деталей по составным частям в естественном порядке.
+ * @return int - Отрицательное, если a раньше b, положительное, если позже, 0 - коды равны.
+ */
+func compareIDs(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareNatural(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+/**
+ * compareNatural: Сравнивает строки в естественном порядке: последовательности цифр - по значению
+ * (любой длины, без преобразования в int), остальное - посимвольно; цифры идут раньше букв.
+ * @return int - Отрицательное, если a раньше b, положительное, если позже, 0 - строки равны по значению.
+ */
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		chunkA, digitsA := nextChunk(a)
+		chunkB, digitsB := nextChunk(b)
+		a, b = a[len(chunkA):], b[len(chunkB):]
+		switch {
+		case digitsA && digitsB:
+			numA, numB := strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+			if len(numA) != len(numB) {
+				return len(numA) - len(numB)
+			}
+			if c := strings.Compare(numA, numB); c != 0 {
+				return c
+			}
+		case digitsA:
+			return -1
+		case digitsB:
+			return 1
+		default:
+			if c := strings.Compare(chunkA, chunkB); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(a) - len(b)
+}
+
+// Возвращает начало строки - последовательность цифр 0-9 или других символов
+func nextChunk(s string) (string, bool) {
+	isDigit := func(c byte) bool {
+		return c >= '0' && c <= '9'
+	}
+	digits := isDigit(s[0])
+	end := 1
+	for end < len(s) && isDigit(s[end]) == digits {
+		end++
+	}
+	return s[:end], digits
+}
+
+/** Возвращает код типа файла на основе его расширения
+ * getFiletypeCode: Ищет значение в карте FileFormats и возвращает соответствующий ключ.
+ * @param storage - Карта для поиска.
+ * @param s - Значение для поиска (например, расширение файла).
+ * @return string - Ключ (код) или пустая строка, если значение не найдено.
+ */
+func getFiletypeCode(storage FileFormats, s string) string {
+	for k, v := range storage {
+		if v == s {
+			return k
+		}
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package worklist_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ProOwler/ListMaker/worklist"
+)
+
+func TestSortFilenames(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		files []string
+		want  []string
+	}{
+		{
+			[]string{"/src/10_1_Крыша.xml", "/src/2_1_Полка.mpr", "/src/1.10_2_Бок.xml", "/src/1.2_3_Дверь.xml", "/src/01_1_Дно.xml"},
+			[]string{"01_1_Дно.xml", "1.2_3_Дверь.xml", "1.10_2_Бок.xml", "2_1_Полка.mpr", "10_1_Крыша.xml"},
+		},
+		// одинаковые коды не теряются и упорядочиваются по имени
+		{
+			[]string{"/src/1.2_1_Полка.xml", "/src/1.2_3_Дверь.xml", "/src/01.02_2_Бок.xml"},
+			[]string{"01.02_2_Бок.xml", "1.2_1_Полка.xml", "1.2_3_Дверь.xml"},
+		},
+		// части больше 999 и длиннее int
+		{
+			[]string{"/src/1.1000_1_Бок.xml", "/src/1.999_1_Бок.xml", "/src/99999999999999999999_1_Дно.xml", "/src/1000_1_Дно.xml"},
+			[]string{"1.999_1_Бок.xml", "1.1000_1_Бок.xml", "1000_1_Дно.xml", "99999999999999999999_1_Дно.xml"},
+		},
+		// буквы в коде: числа по значению, цифры раньше букв; файл без кода первым
+		{
+			[]string{"/src/A10_1_Бок.xml", "/src/A9_1_Бок.xml", "/src/A9b_1_Бок.xml", "/src/9_1_Бок.xml", "/src/_1_Бок.xml"},
+			[]string{"_1_Бок.xml", "9_1_Бок.xml", "A9_1_Бок.xml", "A9b_1_Бок.xml", "A10_1_Бок.xml"},
+		},
+	}
+	for _, test := range tests {
+		// Action
+		got := worklist.SortFilenames(test.files, nil)
+		// Assert
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SortFilenames(%q);\ngot %q;\nwant %q", test.files, got, test.want)
+		}
+	}
+}
+
+func TestFindDuplicateIDs(t *testing.T) {
+	// Arrange
+	files := []string{"/src/1.2_1_Полка.xml", "/src/3_1_Дно.xml", "/src/01.2_3_Дверь.xml", "/src/2_1_Бок.xml", "/src/3_2_Крыша.mpr", "/src/_1_Без_кода.xml", "/src/_2_Без_кода.xml"}
+	want := [][]string{{"01.2_3_Дверь.xml", "1.2_1_Полка.xml"}, {"3_1_Дно.xml", "3_2_Крыша.mpr"}}
+	// Action
+	got := worklist.FindDuplicateIDs(files, nil)
+	// Assert
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDuplicateIDs(%q) = %q; want %q", files, got, want)
+	}
+}
+
+func TestGetOutputXML(t *testing.T) {
+	// Arrange
+	files := []string{"/src/Заказ & Ко/1_2_Бок.xml", "/src/Заказ & Ко/2_1_Фреза.mpr", "/src/Заказ & Ко/3_Без_количества.xml"}
+	// Action
+	got := worklist.GetOutputXML(files, worklist.DefaultFileFormats, worklist.PathMapping{}, nil)
+	// Assert
+	if err := worklist.Verify([]byte(got)); err != nil {
+		t.Fatalf("Verify: %v\n%s", err, got)
+	}
+	for _, want := range []string{"<FilePath>/src/Заказ &amp; Ко/1_2_Бок.xml</FilePath>", "<FileType>7</FileType>", "<SerialNum>1_2_Бок</SerialNum>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("в list.xml нет %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<SerialNum>3_Без_количества</SerialNum>") {
+		t.Errorf("в ProcessList попал файл без количества:\n%s", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// Arrange
+	var tests = []struct {
+		data    string
+		wantErr bool
+	}{
+		{worklist.GetOutputXML([]string{"/src/1_2_Бок.xml"}, worklist.DefaultFileFormats, worklist.PathMapping{}, nil), false},
+		{worklist.GetOutputXML(nil, worklist.DefaultFileFormats, worklist.PathMapping{}, nil), true},
+		{"<WorkList><FileList>", true},
+	}
+	for _, test := range tests {
+		// Action
+		err := worklist.Verify([]byte(test.data))
+		// Assert
+		if (err != nil) != test.wantErr {
+			t.Errorf("Verify(%q) = %v; want error %t", test.data, err, test.wantErr)
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	// Arrange
+	files := []string{"/src/10_1_Крыша.mpr", "/src/2_3_Паз.mpr", "/src/Эскиз.mpr"}
+	mapping := worklist.PathMapping{Rules: []worklist.PathRule{{From: "/src", To: "Z:"}}, Separator: worklist.SeparatorWindows}
+	for _, format := range []string{worklist.FormatWorkList, worklist.FormatText} {
+		// Action
+		data := []byte(worklist.Generate(format, files, worklist.DefaultFileFormats, mapping, nil))
+		paths, err := worklist.ReadFilePaths(format, data)
+		// Assert
+		if err != nil || worklist.VerifyFormat(format)(data) != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		sort.Strings(paths)
+		if want := []string{`Z:\10_1_Крыша.mpr`, `Z:\2_3_Паз.mpr`, `Z:\Эскиз.mpr`}; !reflect.DeepEqual(paths, want) {
+			t.Errorf("%s: ReadFilePaths = %q; want %q", format, paths, want)
+		}
+	}
+	if text := worklist.GetOutputText(files, worklist.PathMapping{}, nil); text != "/src/2_3_Паз.mpr\t3\r\n/src/10_1_Крыша.mpr\t1\r\n/src/Эскиз.mpr\t\r\n" {
+		t.Errorf("GetOutputText = %q", text)
+	}
+	if worklist.VerifyText([]byte("\r\n")) == nil {
+		t.Error("пустой текстовый список прошёл проверку")
+	}
+}
+
+func TestPathMapping(t *testing.T) {
+	// Arrange
+	rules := []worklist.PathRule{{From: "/mnt/share", To: `\\server\Заказы`}, {From: "/home", To: "Z:"}}
+	var tests = []struct {
+		mapping worklist.PathMapping
+		path    string
+		want    string
+	}{
+		{worklist.PathMapping{}, "/mnt/share/Иванов/1_2_Бок.xml", "/mnt/share/Иванов/1_2_Бок.xml"},
+		{worklist.PathMapping{Rules: rules, Separator: worklist.SeparatorWindows}, "/mnt/share/Иванов/1_2_Бок.xml", `\\server\Заказы\Иванов\1_2_Бок.xml`},
+		{worklist.PathMapping{Rules: rules, Separator: worklist.SeparatorWindows}, "/MNT/Share/Иванов/1_2_Бок.xml", `\\server\Заказы\Иванов\1_2_Бок.xml`},
+		{worklist.PathMapping{Rules: rules, Separator: worklist.SeparatorWindows}, "/mnt/shared/1_2_Бок.xml", `\mnt\shared\1_2_Бок.xml`},
+		{worklist.PathMapping{Rules: rules}, "/home/Иванов/1_2_Бок.xml", "Z:/Иванов/1_2_Бок.xml"},
+		{worklist.PathMapping{Relative: true, Rules: rules}, "/mnt/share/Иванов/1_2_Бок.xml", "1_2_Бок.xml"},
+	}
+	for _, test := range tests {
+		// Action
+		got := test.mapping.Apply(test.path, "/mnt/share/Иванов")
+		back := test.mapping.Resolve(got, "/mnt/share/Иванов")
+		// Assert
+		if got != test.want {
+			t.Errorf("%+v.Apply(%q) = %q; want %q", test.mapping, test.path, got, test.want)
+		}
+		if back != test.path && !strings.EqualFold(back, test.path) {
+			t.Errorf("%+v.Resolve(%q) = %q; want %q", test.mapping, got, back, test.path)
+		}
+	}
+	if err := (worklist.PathMapping{Separator: "mac"}).Validate(); err == nil {
+		t.Error("неизвестный разделитель принят")
+	}
+	if err := (worklist.PathMapping{Rules: []worklist.PathRule{{From: "", To: "Z:"}}}).Validate(); err == nil {
+		t.Error("замена без начала пути принята")
+	}
+}
+
+func FuzzSortFilenames(f *testing.F) {
+	f.Add("1.2_3_Дверь.xml", "10_1_Крыша.xml", "1.2_1_Полка.xml")
+	f.Add("-1001_1_Бок.xml", "9223372036854775807_1_Дно.xml", "01_1_Дно.xml")
+	f.Add("_", "", "A9b_1")
+	f.Fuzz(func(t *testing.T, first string, second string, third string) {
+		files := []string{"/src/" + first, "/src/" + second, "/src/" + third}
+		got := worklist.SortFilenames(files, nil)
+		want := make([]string, len(files))
+		for i, file := range files {
+			want[i] = filepath.Base(file)
+		}
+		sort.Strings(want)
+		sorted := append([]string(nil), got...)
+		sort.Strings(sorted)
+		if !reflect.DeepEqual(sorted, want) {
+			t.Fatalf("SortFilenames(%q) = %q - файлы потеряны или изменены", files, got)
+		}
+		// порядок не зависит от исходного
+		reversed := []string{files[2], files[1], files[0]}
+		if again := worklist.SortFilenames(reversed, nil); !reflect.DeepEqual(again, got) {
+			t.Errorf("SortFilenames(%q) = %q, а SortFilenames(%q) = %q", files, got, reversed, again)
+		}
+	})
+}
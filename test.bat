@@ -1,3 +1,3 @@
 cd src
-D:\distr\go1.20.14.windows-386\go\bin\go test -v
+D:\distr\go1.20.14.windows-386\go\bin\go test ./...
 pause